@@ -0,0 +1,209 @@
+package belajar_golang_web
+
+import (
+	"bytes"             // Untuk membuat body multipart di test
+	"context"           // Untuk mengenali request yang dibatalkan client
+	"errors"            // Untuk errors.Is
+	"fmt"               // Untuk logging dan output ke console
+	"io"                // Untuk membaca body response
+	"mime/multipart"    // Untuk membuat form upload di test
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"os"                // Untuk membaca file hasil upload di test
+	"path/filepath"     // Untuk path file hasil upload di test
+	"strings"           // Untuk body form di test
+	"testing"           // Untuk unit testing
+)
+
+// Handler yang mengembalikan error alih-alih panic
+// Error ditangani di satu tempat oleh ServeHTTP (lewat WriteProblem)
+type AppHandler func(writer http.ResponseWriter, request *http.Request) error
+
+// ResponseWriter yang mencatat apakah response sudah mulai dikirim beserta status-nya
+type trackingResponseWriter struct {
+	http.ResponseWriter
+	started bool
+	status  int
+}
+
+func (writer *trackingResponseWriter) WriteHeader(status int) {
+	if writer.status == 0 && status >= http.StatusOK {
+		writer.status = status
+	}
+	writer.started = true
+	writer.ResponseWriter.WriteHeader(status)
+}
+
+func (writer *trackingResponseWriter) Write(data []byte) (int, error) {
+	if writer.status == 0 {
+		writer.status = http.StatusOK
+	}
+	writer.started = true
+	return writer.ResponseWriter.Write(data)
+}
+
+// Tetap memenuhi http.Flusher agar streaming seperti Server-Sent Events bisa dipakai
+func (writer *trackingResponseWriter) Flush() {
+	if writer.status == 0 {
+		writer.status = http.StatusOK
+	}
+	writer.started = true
+	http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+// Agar http.ResponseController tetap bisa Flush dan lainnya
+func (writer *trackingResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// Adapter AppHandler menjadi http.Handler
+func (handler AppHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	tracking := &trackingResponseWriter{ResponseWriter: writer}
+
+	err := handler(tracking, request)
+	if err == nil {
+		return
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// Client sudah menutup koneksi, tidak ada yang perlu dikirim
+		fmt.Printf("Client closed request : method=%s path=%s\n", request.Method, request.URL.Path)
+	case tracking.started:
+		// Sebagian response sudah terkirim, status tidak bisa diubah lagi
+		fmt.Printf("Error after response started : method=%s path=%s error=%v\n", request.Method, request.URL.Path, err)
+	default:
+		WriteProblem(writer, request, err)
+	}
+}
+
+// Membungkus AppHandler agar bisa didaftarkan dengan mux.Handle
+func Handle(handler func(writer http.ResponseWriter, request *http.Request) error) http.Handler {
+	return AppHandler(handler)
+}
+
+// Error dari sisi client (4xx): input salah, data tidak ditemukan, dan sebagainya
+func IsClientError(err error) bool {
+	status := defaultErrorRegistry.Problem(err).Status
+	return status >= 400 && status < 500
+}
+
+func appHandlerMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", UploadForm)
+	mux.Handle("/upload", Handle(Upload))
+	mux.Handle("/form", Handle(FormPost))
+	mux.Handle("/fail", Handle(func(writer http.ResponseWriter, request *http.Request) error {
+		return errors.New("koneksi database terputus")
+	}))
+	mux.Handle("/partial", Handle(func(writer http.ResponseWriter, request *http.Request) error {
+		fmt.Fprint(writer, "sebagian response")
+		return errors.New("gagal di tengah jalan")
+	}))
+	return mux
+}
+
+func TestAppHandler(t *testing.T) {
+	mux := appHandlerMux()
+
+	tests := []struct {
+		method      string
+		target      string
+		body        string
+		contentType string
+		status      int
+	}{
+		{http.MethodPost, "/form", "first_name=Hilmi&last_name=Yahya", "application/x-www-form-urlencoded", http.StatusOK},
+		{http.MethodPost, "/form", "%zz", "application/x-www-form-urlencoded", http.StatusBadRequest},
+		{http.MethodPost, "/upload", "", "", http.StatusBadRequest},
+		{http.MethodGet, "/fail", "", "", http.StatusInternalServerError},
+		{http.MethodGet, "/partial", "", "", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, "http://localhost:8080"+test.target, strings.NewReader(test.body))
+		if test.contentType != "" {
+			request.Header.Set("Content-Type", test.contentType)
+		}
+		recorder := httptest.NewRecorder()
+
+		mux.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(test.target, response.StatusCode, string(body))
+
+		if response.StatusCode != test.status {
+			t.Errorf("%s %s: expected %d, got %d", test.method, test.target, test.status, response.StatusCode)
+		}
+	}
+}
+
+func TestAppHandlerUpload(t *testing.T) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	writer.WriteField("name", "Hilmi Yahya")
+	file, _ := writer.CreateFormFile("file", "contoh-upload.png")
+	file.Write(uploadFileTest)
+	writer.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:8080/upload", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	recorder := httptest.NewRecorder()
+
+	// File hasil upload ditulis ke folder sementara, bukan ke ./resources
+	directory := t.TempDir()
+	previous := uploadDirectory
+	uploadDirectory = directory
+	t.Cleanup(func() { uploadDirectory = previous })
+
+	appHandlerMux().ServeHTTP(recorder, request)
+
+	bodyResponse, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(recorder.Result().StatusCode)
+	fmt.Println(string(bodyResponse))
+
+	if recorder.Result().StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Result().StatusCode)
+	}
+	uploaded, err := os.ReadFile(filepath.Join(directory, "contoh-upload.png"))
+	if err != nil || !bytes.Equal(uploaded, uploadFileTest) {
+		t.Errorf("uploaded file not written to %s: %v", directory, err)
+	}
+}
+
+func TestIsClientError(t *testing.T) {
+	tests := []struct {
+		err    error
+		client bool
+	}{
+		{ValidationErrors{{Field: "name", Message: "wajib diisi"}}, true},
+		{NewProblem(http.StatusConflict, "upload dengan ID ini sedang berjalan"), true},
+		{fmt.Errorf("membaca halaman: %w", ErrRecordNotFound), true},
+		{errors.New("koneksi database terputus"), false},
+		{context.DeadlineExceeded, false},
+	}
+
+	for _, test := range tests {
+		client := IsClientError(test.err)
+		fmt.Println(test.err, client)
+		if client != test.client {
+			t.Errorf("%v: expected client error %v, got %v", test.err, test.client, client)
+		}
+	}
+}
+
+func TestAppHandlerServer(t *testing.T) {
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: appHandlerMux(),
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat tipe AppHandler, yaitu handler yang mengembalikan error alih-alih panic, beserta adapter yang menjadikannya http.Handler. Error ditangani di satu tempat: error client (4xx) dan error server (5xx) diklasifikasikan lewat ErrorRegistry, error server dicatat ke log beserta konteks request, lalu response ditulis oleh WriteProblem sebagai Problem Details atau halaman error HTML. Upload dan FormPost diporting menjadi AppHandler tanpa mengubah route-nya, cukup didaftarkan dengan mux.Handle dan Handle.
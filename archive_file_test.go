@@ -0,0 +1,387 @@
+package belajar_golang_web
+
+import (
+	"archive/tar"       // Untuk membuat arsip .tar
+	"archive/zip"       // Untuk membuat arsip .zip
+	"bytes"             // Untuk membaca hasil arsip saat test
+	"compress/gzip"     // Untuk mengompres arsip tar menjadi .tar.gz
+	"fmt"               // Untuk menulis response dan output ke console
+	"io"                // Untuk operasi copy data stream
+	"io/fs"             // Untuk informasi file (FileInfo) dan WalkDir
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"os"                // Untuk membuka file dari file system
+	"path/filepath"     // Untuk validasi dan penggabungan path file
+	"sort"              // Untuk mengurutkan daftar file
+	"strings"           // Untuk membandingkan isi manifest di test
+	"testing"           // Untuk unit testing
+)
+
+// Folder sumber file yang boleh didownload
+const downloadDirectory = "./resources"
+
+// Memvalidasi nama file dari user agar tidak bisa keluar dari folder resources
+// (misal "../go.mod" atau "/etc/passwd"), lalu mengembalikan path lengkapnya
+func safeResourcePath(file string) (string, bool) {
+	if file == "" || !filepath.IsLocal(file) {
+		return "", false
+	}
+	return filepath.Join(downloadDirectory, file), true
+}
+
+// Interface kecil agar handler bisa menulis ke zip maupun tar.gz dengan cara yang sama
+type archiveWriter interface {
+	AddFile(name string, info fs.FileInfo, reader io.Reader) error
+	AddManifest(content []byte) error
+	Close() error
+}
+
+// Implementasi archiveWriter untuk format zip
+type zipArchiveWriter struct {
+	writer *zip.Writer
+}
+
+func (archive *zipArchiveWriter) AddFile(name string, info fs.FileInfo, reader io.Reader) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name          // Nama file di dalam arsip (relatif)
+	header.Method = zip.Deflate // Kompres isi file
+
+	fileWriter, err := archive.writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fileWriter, reader)
+	return err
+}
+
+func (archive *zipArchiveWriter) AddManifest(content []byte) error {
+	fileWriter, err := archive.writer.Create("MANIFEST.txt")
+	if err != nil {
+		return err
+	}
+	_, err = fileWriter.Write(content)
+	return err
+}
+
+func (archive *zipArchiveWriter) Close() error {
+	return archive.writer.Close()
+}
+
+// Implementasi archiveWriter untuk format tar.gz
+type tarGzipArchiveWriter struct {
+	gzipWriter *gzip.Writer
+	writer     *tar.Writer
+}
+
+func (archive *tarGzipArchiveWriter) AddFile(name string, info fs.FileInfo, reader io.Reader) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+
+	err = archive.writer.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(archive.writer, reader)
+	return err
+}
+
+func (archive *tarGzipArchiveWriter) AddManifest(content []byte) error {
+	err := archive.writer.WriteHeader(&tar.Header{
+		Name: "MANIFEST.txt",
+		Mode: 0644,
+		Size: int64(len(content)),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = archive.writer.Write(content)
+	return err
+}
+
+func (archive *tarGzipArchiveWriter) Close() error {
+	err := archive.writer.Close()
+	if err != nil {
+		return err
+	}
+	return archive.gzipWriter.Close()
+}
+
+// Mengumpulkan daftar file dari parameter ?file= dan ?dir=
+// File yang tidak valid tidak menghentikan proses, tetapi dicatat ke manifest
+func collectArchiveFiles(files []string, directory string, manifest *bytes.Buffer) []string {
+	var names []string
+
+	for _, file := range files {
+		if _, ok := safeResourcePath(file); !ok {
+			fmt.Fprintf(manifest, "INVALID %s\n", file)
+			continue
+		}
+		names = append(names, filepath.ToSlash(filepath.Clean(file)))
+	}
+
+	if directory != "" {
+		root, ok := safeResourcePath(directory)
+		if !ok {
+			fmt.Fprintf(manifest, "INVALID %s/\n", directory)
+			return names
+		}
+
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			name, err := filepath.Rel(downloadDirectory, path)
+			if err != nil {
+				return err
+			}
+			names = append(names, filepath.ToSlash(name))
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(manifest, "MISSING %s/\n", directory)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Menambahkan satu file ke arsip, error dikembalikan agar bisa dicatat di manifest
+func addArchiveFile(archive archiveWriter, name string) error {
+	path, _ := safeResourcePath(name)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fs.ErrInvalid
+	}
+
+	return archive.AddFile(name, info, file)
+}
+
+// Handler untuk mendownload banyak file sekaligus dalam bentuk zip atau tar.gz
+// Contoh: /archive?file=index.css&file=index.js&format=zip atau /archive?dir=.&format=tar.gz
+func DownloadArchive(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	files := query["file"]        // Bisa lebih dari satu ?file=
+	directory := query.Get("dir") // Prefix folder di dalam resources
+	format := query.Get("format") // zip (default) atau tar.gz
+
+	// Validasi jika tidak ada file maupun folder yang diminta
+	if len(files) == 0 && directory == "" {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(writer, "Bad Request")
+		return
+	}
+
+	var archive archiveWriter
+	var filename string
+
+	// Arsip ditulis langsung ke response, tanpa file sementara
+	switch format {
+	case "", "zip":
+		writer.Header().Set("Content-Type", "application/zip")
+		filename = "resources.zip"
+		archive = &zipArchiveWriter{writer: zip.NewWriter(writer)}
+	case "tar.gz", "tgz":
+		writer.Header().Set("Content-Type", "application/gzip")
+		filename = "resources.tar.gz"
+		gzipWriter := gzip.NewWriter(writer)
+		archive = &tarGzipArchiveWriter{gzipWriter: gzipWriter, writer: tar.NewWriter(gzipWriter)}
+	default:
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(writer, "Bad Request")
+		return
+	}
+
+	writer.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+
+	manifest := new(bytes.Buffer)
+	names := collectArchiveFiles(files, directory, manifest)
+
+	for _, name := range names {
+		err := addArchiveFile(archive, name)
+		if err != nil {
+			// Response sudah terkirim sebagian, jadi error dicatat di manifest saja
+			fmt.Fprintf(manifest, "MISSING %s\n", name)
+			continue
+		}
+		fmt.Fprintf(manifest, "OK %s\n", name)
+	}
+
+	err := archive.AddManifest(manifest.Bytes())
+	if err != nil {
+		fmt.Println("Gagal menulis manifest :", err)
+	}
+
+	err = archive.Close()
+	if err != nil {
+		fmt.Println("Gagal menutup arsip :", err)
+	}
+}
+
+// Membaca isi zip dari response, mengembalikan daftar nama file dan isi MANIFEST.txt
+func readZipArchive(t *testing.T, body []byte) ([]string, string) {
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	var manifest string
+	for _, file := range reader.File {
+		fmt.Println(file.Name, file.UncompressedSize64)
+		names = append(names, file.Name)
+		if file.Name == "MANIFEST.txt" {
+			content, _ := file.Open()
+			data, _ := io.ReadAll(content)
+			manifest = string(data)
+		}
+	}
+	return names, manifest
+}
+
+// Test download zip tanpa menjalankan server sungguhan
+func TestDownloadArchiveZip(t *testing.T) {
+	request := httptest.NewRequest(
+		http.MethodGet,
+		"http://localhost:8080/archive?file=index.css&file=index.js&file=tidak-ada.txt&file=../go.mod",
+		nil,
+	)
+	recorder := httptest.NewRecorder()
+
+	DownloadArchive(recorder, request)
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+
+	fmt.Println(response.Header.Get("Content-Type"))
+	fmt.Println(response.Header.Get("Content-Disposition"))
+	if response.Header.Get("Content-Type") != "application/zip" {
+		t.Errorf("unexpected Content-Type %q", response.Header.Get("Content-Type"))
+	}
+
+	// Membaca kembali isi zip untuk melihat daftar file
+	names, manifest := readZipArchive(t, body)
+	fmt.Print(manifest)
+
+	expectedNames := []string{"index.css", "index.js", "MANIFEST.txt"}
+	if strings.Join(names, ",") != strings.Join(expectedNames, ",") {
+		t.Errorf("expected files %v, got %v", expectedNames, names)
+	}
+
+	// File di luar resources ditolak, file yang tidak ada dicatat tanpa menggagalkan arsip
+	expectedManifest := "INVALID ../go.mod\nOK index.css\nOK index.js\nMISSING tidak-ada.txt\n"
+	if manifest != expectedManifest {
+		t.Errorf("expected manifest %q, got %q", expectedManifest, manifest)
+	}
+}
+
+// Test folder yang keluar dari resources dan folder yang tidak ada
+func TestDownloadArchiveInvalidDirectory(t *testing.T) {
+	tests := []struct {
+		directory string
+		manifest  string
+	}{
+		{"..", "INVALID ../\n"},
+		{"tidak-ada", "MISSING tidak-ada/\n"},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/archive?dir="+test.directory, nil)
+		recorder := httptest.NewRecorder()
+
+		DownloadArchive(recorder, request)
+
+		body, _ := io.ReadAll(recorder.Result().Body)
+		names, manifest := readZipArchive(t, body)
+		fmt.Print(manifest)
+
+		if len(names) != 1 || manifest != test.manifest {
+			t.Errorf("dir=%s: expected only manifest %q, got files %v and manifest %q", test.directory, test.manifest, names, manifest)
+		}
+	}
+}
+
+// Test download tar.gz satu folder penuh
+func TestDownloadArchiveTarGzip(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/archive?dir=.&format=tar.gz", nil)
+	recorder := httptest.NewRecorder()
+
+	DownloadArchive(recorder, request)
+
+	response := recorder.Result()
+	fmt.Println(response.Header.Get("Content-Disposition"))
+
+	gzipReader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := tar.NewReader(gzipReader)
+
+	files := map[string]bool{}
+	var manifest string
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println(header.Name, header.Size)
+		files[header.Name] = true
+		if header.Name == "MANIFEST.txt" {
+			data, _ := io.ReadAll(reader)
+			manifest = string(data)
+		}
+	}
+
+	// Setiap file di folder tercatat OK di manifest dan benar-benar ada di arsip
+	lines := strings.Split(strings.TrimSuffix(manifest, "\n"), "\n")
+	if !files["index.css"] || !files["MANIFEST.txt"] || len(lines) != len(files)-1 {
+		t.Errorf("archive and manifest do not match: %d files, manifest %q", len(files), manifest)
+	}
+	for _, line := range lines {
+		name, ok := strings.CutPrefix(line, "OK ")
+		if !ok || !files[name] {
+			t.Errorf("unexpected manifest line %q", line)
+		}
+	}
+}
+
+// Test untuk menjalankan HTTP server secara manual
+func TestDownloadArchiveServer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/archive", DownloadArchive)
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: mux,
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini menambahkan fitur download banyak file sekaligus dalam bentuk arsip zip atau tar.gz yang dibuat langsung (streaming) ke response tanpa file sementara. Nama file divalidasi dengan aturan yang sama seperti DownloadFile agar tidak bisa keluar dari folder resources, dan file yang tidak ditemukan tidak menghentikan proses, melainkan dicatat ke dalam MANIFEST.txt di akhir arsip.
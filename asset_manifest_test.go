@@ -0,0 +1,393 @@
+package belajar_golang_web
+
+import (
+	"encoding/json"     // Untuk membuat file manifest JSON
+	"fmt"               // Untuk menampilkan output ke console
+	"html/template"     // Untuk function template "asset"
+	"io"                // Untuk membaca body response
+	"io/fs"             // Abstraksi filesystem (embed maupun disk)
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"os"                // Untuk menulis file manifest ke disk
+	"path"              // Untuk memecah nama file dan ekstensi
+	"path/filepath"     // Untuk path file manifest di test
+	"strings"           // Untuk manipulasi string
+	"sync"              // Untuk mengamankan manifest yang diperbarui saat file berubah
+	"testing"           // Untuk unit testing
+)
+
+// Cache-Control untuk asset yang namanya sudah mengandung hash,
+// isi file tidak akan pernah berubah untuk URL yang sama
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// Pemetaan nama asset asli ke nama yang mengandung hash (fingerprint)
+// Contoh: index.css -> index.3f2a9c1b.css
+type AssetManifest struct {
+	Prefix     string            // Prefix URL, misal "/static"
+	FileSystem fs.FS             // Sumber file: embed.FS, os.DirFS, atau OverlayFS
+	Assets     map[string]string // Nama asli -> nama fingerprint
+
+	mutex   sync.RWMutex
+	reverse map[string]string         // Nama fingerprint -> nama asli
+	hashes  map[string]staticFileHash // Hash terakhir per nama asli beserta ukuran dan waktu modifikasi
+}
+
+// Menambahkan hash ke nama file sebelum ekstensi
+func fingerprintName(name string, hash string) string {
+	extension := path.Ext(name)
+	return strings.TrimSuffix(name, extension) + "." + hash + extension
+}
+
+// Membuat manifest dengan menghitung hash seluruh file saat startup
+func NewAssetManifest(fileSystem fs.FS, prefix string) (*AssetManifest, error) {
+	manifest := &AssetManifest{
+		Prefix:     prefix,
+		FileSystem: fileSystem,
+		Assets:     map[string]string{},
+		reverse:    map[string]string{},
+		hashes:     map[string]staticFileHash{},
+	}
+
+	err := fs.WalkDir(fileSystem, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// index.html dilayani FileServer dengan redirect ke folder-nya, sehingga nama
+		// fingerprint-nya akan menghasilkan redirect ber-Cache-Control immutable
+		if entry.IsDir() || path.Base(name) == "index.html" {
+			return nil
+		}
+		_, err = manifest.add(name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Menghitung hash file lalu mencatat nama fingerprint-nya
+func (manifest *AssetManifest) add(name string) (string, error) {
+	// Memakai hash yang sama dengan ETag pada StaticHandler
+	hash, err := computeStaticHash(manifest.FileSystem, name)
+	if err != nil {
+		return "", err
+	}
+	fingerprinted := fingerprintName(name, strings.Trim(hash.ETag, `"`)[:8])
+
+	manifest.mutex.Lock()
+	defer manifest.mutex.Unlock()
+	manifest.Assets[name] = fingerprinted
+	manifest.reverse[fingerprinted] = name
+	manifest.hashes[name] = hash
+	return fingerprinted, nil
+}
+
+// Nama fingerprint sesuai isi file saat ini, false jika file tidak dikenal atau sudah dihapus
+// Hash dihitung ulang jika ukuran atau waktu modifikasi berubah (folder disk atau overlay),
+// file embed tidak memiliki waktu modifikasi sehingga hash-nya tidak pernah dihitung ulang
+func (manifest *AssetManifest) fingerprint(name string) (string, bool) {
+	manifest.mutex.RLock()
+	fingerprinted, ok := manifest.Assets[name]
+	cached := manifest.hashes[name]
+	manifest.mutex.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	info, err := fs.Stat(manifest.FileSystem, name)
+	if err != nil {
+		return "", false
+	}
+	if cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+		return fingerprinted, true
+	}
+
+	fingerprinted, err = manifest.add(name)
+	return fingerprinted, err == nil
+}
+
+// Mengembalikan URL asset yang sudah di-fingerprint, atau URL biasa jika tidak dikenal
+func (manifest *AssetManifest) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	fingerprinted, ok := manifest.fingerprint(name)
+	if !ok {
+		fingerprinted = name
+	}
+	return manifest.Prefix + "/" + fingerprinted
+}
+
+// Function map yang didaftarkan ke template, dipakai dengan {{asset "index.css"}}
+func (manifest *AssetManifest) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"asset": manifest.URL,
+	}
+}
+
+// Menulis manifest dalam format JSON untuk tooling eksternal
+func (manifest *AssetManifest) WriteJSON(writer io.Writer) error {
+	manifest.mutex.RLock()
+	defer manifest.mutex.RUnlock()
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(manifest.Assets)
+}
+
+// Menyimpan manifest JSON ke file
+func (manifest *AssetManifest) WriteFile(name string) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return manifest.WriteJSON(file)
+}
+
+// ResponseWriter yang memasang Cache-Control immutable hanya pada response sukses,
+// sehingga 404 atau error lain untuk URL fingerprint tidak ikut di-cache setahun
+type immutableResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (writer *immutableResponseWriter) WriteHeader(status int) {
+	if !writer.wroteHeader {
+		writer.wroteHeader = true
+		// 304 adalah revalidasi dari response 200 yang sama, sehingga memakai header yang sama
+		if status == http.StatusOK || status == http.StatusNotModified {
+			writer.Header().Set("Cache-Control", immutableCacheControl)
+		}
+	}
+	writer.ResponseWriter.WriteHeader(status)
+}
+
+func (writer *immutableResponseWriter) Write(data []byte) (int, error) {
+	if !writer.wroteHeader {
+		writer.WriteHeader(http.StatusOK)
+	}
+	return writer.ResponseWriter.Write(data)
+}
+
+// Agar http.ResponseController tetap bisa Flush dan lainnya
+func (writer *immutableResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// Handler yang melayani nama asset asli maupun nama fingerprint
+// Nama fingerprint diarahkan ke file asli dengan Cache-Control immutable,
+// tetapi hanya jika hash-nya masih sama dengan isi file saat ini
+func (manifest *AssetManifest) Handler(static http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		name := strings.TrimPrefix(request.URL.Path, "/")
+
+		manifest.mutex.RLock()
+		original, ok := manifest.reverse[name]
+		manifest.mutex.RUnlock()
+
+		if ok {
+			// File sudah berubah atau dihapus sejak URL ini dibuat, isi lama tidak tersedia lagi
+			current, found := manifest.fingerprint(original)
+			if !found || current != name {
+				http.NotFound(writer, request)
+				return
+			}
+
+			// Mengganti path request ke nama file asli sebelum diteruskan
+			request = request.Clone(request.Context())
+			request.URL.Path = "/" + original
+			request.URL.RawPath = ""
+			writer = &immutableResponseWriter{ResponseWriter: writer}
+		}
+
+		static.ServeHTTP(writer, request)
+	})
+}
+
+func TestAssetManifest(t *testing.T) {
+	directory, _ := fs.Sub(resources, "resources")
+	manifest, err := NewAssetManifest(directory, "/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Menampilkan manifest JSON ke console dan menyimpannya ke file
+	manifest.WriteJSON(os.Stdout)
+
+	err = manifest.WriteFile(filepath.Join(t.TempDir(), "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := manifest.Assets["index.html"]; ok {
+		t.Error("index.html should not be fingerprinted")
+	}
+	if manifest.URL("index.html") != "/static/index.html" {
+		t.Errorf("unexpected index.html URL %s", manifest.URL("index.html"))
+	}
+}
+
+func TestAssetTemplateFunction(t *testing.T) {
+	directory, _ := fs.Sub(resources, "resources")
+	manifest, err := NewAssetManifest(directory, "/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Template menggunakan function asset untuk mendapatkan URL fingerprint
+	tmpl := template.Must(template.New("ASSET").Funcs(manifest.FuncMap()).Parse(
+		`<link rel="stylesheet" href="{{asset "index.css"}}"><script src="{{asset "index.js"}}"></script>`,
+	))
+
+	builder := new(strings.Builder)
+	err = tmpl.ExecuteTemplate(builder, "ASSET", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(builder.String())
+
+	expected := `<link rel="stylesheet" href="` + manifest.URL("index.css") + `"><script src="` + manifest.URL("index.js") + `"></script>`
+	if builder.String() != expected || manifest.URL("index.css") == "/static/index.css" {
+		t.Errorf("unexpected output %s", builder.String())
+	}
+}
+
+func TestAssetHandler(t *testing.T) {
+	directory, _ := fs.Sub(resources, "resources")
+	manifest, err := NewAssetManifest(directory, "/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	static, err := NewStaticHandler(directory, staticCachePolicies...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", manifest.Handler(static)))
+
+	// Request ke URL fingerprint dan URL biasa
+	tests := []struct {
+		url          string
+		status       int
+		cacheControl string
+	}{
+		{manifest.URL("index.css"), http.StatusOK, immutableCacheControl},
+		{"/static/index.css", http.StatusOK, "public, max-age=3600"},
+		{"/static/index.html", http.StatusMovedPermanently, "no-cache"},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+test.url, nil)
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(test.url, response.StatusCode, response.Header.Get("Cache-Control"))
+		fmt.Println(string(body))
+
+		if response.StatusCode != test.status || response.Header.Get("Cache-Control") != test.cacheControl {
+			t.Errorf("%s: expected %d %q, got %d %q", test.url, test.status, test.cacheControl, response.StatusCode, response.Header.Get("Cache-Control"))
+		}
+	}
+}
+
+func TestAssetManifestDisk(t *testing.T) {
+	directory := t.TempDir()
+	err := os.WriteFile(filepath.Join(directory, "app.css"), []byte("body { color: red; }"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(directory, "hapus.js"), []byte("console.log('hapus')"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileSystem := os.DirFS(directory)
+	manifest, err := NewAssetManifest(fileSystem, "/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	static, err := NewStaticHandler(fileSystem, staticCachePolicies...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", manifest.Handler(static)))
+
+	get := func(url string) (*http.Response, string) {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+url, nil)
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(url, response.StatusCode, response.Header.Get("Cache-Control"), string(body))
+		return response, string(body)
+	}
+
+	// File diubah setelah manifest dibuat: URL baru mengikuti isi baru, URL lama tidak lagi dilayani
+	oldURL := manifest.URL("app.css")
+	err = os.WriteFile(filepath.Join(directory, "app.css"), []byte("body { color: blue; margin: 0; }"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newURL := manifest.URL("app.css")
+	if newURL == oldURL {
+		t.Fatalf("fingerprint not updated after edit: %s", newURL)
+	}
+
+	response, body := get(newURL)
+	if response.StatusCode != http.StatusOK || response.Header.Get("Cache-Control") != immutableCacheControl || body != "body { color: blue; margin: 0; }" {
+		t.Errorf("%s: unexpected response %d %q", newURL, response.StatusCode, response.Header.Get("Cache-Control"))
+	}
+	response, _ = get(oldURL)
+	if response.StatusCode != http.StatusNotFound || response.Header.Get("Cache-Control") == immutableCacheControl {
+		t.Errorf("%s: expected 404 without immutable, got %d %q", oldURL, response.StatusCode, response.Header.Get("Cache-Control"))
+	}
+
+	// File yang dihapus dibalas 404 tanpa Cache-Control immutable
+	deletedURL := manifest.URL("hapus.js")
+	err = os.Remove(filepath.Join(directory, "hapus.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, _ = get(deletedURL)
+	if response.StatusCode != http.StatusNotFound || response.Header.Get("Cache-Control") == immutableCacheControl {
+		t.Errorf("%s: expected 404 without immutable, got %d %q", deletedURL, response.StatusCode, response.Header.Get("Cache-Control"))
+	}
+}
+
+func TestAssetServer(t *testing.T) {
+	directory, _ := fs.Sub(resources, "resources")
+	manifest, err := NewAssetManifest(directory, "/static")
+	if err != nil {
+		panic(err)
+	}
+	static, err := NewStaticHandler(directory, staticCachePolicies...)
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", manifest.Handler(static)))
+	mux.HandleFunc("/asset-manifest.json", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		manifest.WriteJSON(writer)
+	})
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: mux,
+	}
+
+	err = server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat asset manifest yang memetakan setiap file di resources ke nama yang mengandung hash isinya, sehingga file seperti index.css dapat di-cache selamanya (immutable) tanpa risiko client memakai versi lama. Template cukup memanggil {{asset "index.css"}} untuk mendapatkan URL fingerprint di bawah /static, handler tetap melayani nama asli maupun nama fingerprint, dan manifest dapat ditulis sebagai JSON untuk kebutuhan tooling eksternal. Untuk folder disk atau overlay, hash dihitung ulang ketika ukuran atau waktu modifikasi file berubah sehingga URL fingerprint lama tidak lagi dilayani dengan isi baru, dan Cache-Control immutable hanya dipasang pada response yang berhasil.
@@ -0,0 +1,521 @@
+package belajar_golang_web
+
+import (
+	"bytes"             // Untuk membuat data test
+	"compress/gzip"     // Encoder gzip bawaan Go
+	"compress/zlib"     // Format zlib untuk Content-Encoding: deflate
+	"fmt"               // Untuk menampilkan output ke console
+	"io"                // Untuk operasi stream
+	"io/fs"             // Abstraksi filesystem untuk file precompressed
+	"mime"              // Untuk menentukan Content-Type dari ekstensi
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"path"              // Untuk ekstensi dan pembersihan path
+	"strconv"           // Untuk parsing nilai q pada Accept-Encoding
+	"strings"           // Untuk manipulasi string
+	"testing"           // Untuk unit testing
+	"testing/fstest"    // Filesystem di memory untuk test file .gz
+	"time"              // Untuk waktu modifikasi file
+)
+
+// Interface encoder kompresi, bisa ditambah implementasi lain (misal brotli)
+type Encoder interface {
+	Encoding() string                                   // Nilai Content-Encoding, misal "gzip"
+	NewWriter(writer io.Writer) (io.WriteCloser, error) // Membungkus writer dengan kompresor
+}
+
+// Encoder gzip
+type GzipEncoder struct {
+	Level int // Level kompresi gzip
+}
+
+func (encoder GzipEncoder) Encoding() string {
+	return "gzip"
+}
+
+func (encoder GzipEncoder) NewWriter(writer io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(writer, encoder.Level)
+}
+
+// Encoder deflate
+// Content-Encoding "deflate" di HTTP berarti data DEFLATE dalam format zlib (RFC 9110 bagian 8.4.1.2),
+// bukan DEFLATE mentah dari compress/flate
+type DeflateEncoder struct {
+	Level int // Level kompresi zlib
+}
+
+func (encoder DeflateEncoder) Encoding() string {
+	return "deflate"
+}
+
+func (encoder DeflateEncoder) NewWriter(writer io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriterLevel(writer, encoder.Level)
+}
+
+// Content-Type yang sudah terkompresi sehingga tidak perlu dikompres ulang
+var incompressibleTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"video/", "audio/",
+	"application/zip", "application/gzip", "application/x-gzip",
+	"font/woff", "font/woff2",
+}
+
+// Middleware untuk mengompres response berdasarkan header Accept-Encoding
+type CompressionMiddleware struct {
+	Handler    http.Handler // Handler yang dibungkus middleware
+	Encoders   []Encoder    // Urutan encoder sesuai preferensi server
+	MinSize    int          // Ukuran minimum body yang dikompres (byte)
+	FileSystem fs.FS        // Opsional, sumber file precompressed (.gz)
+}
+
+// Membuat CompressionMiddleware dengan gzip dan deflate sebagai default
+func NewCompressionMiddleware(handler http.Handler) *CompressionMiddleware {
+	return &CompressionMiddleware{
+		Handler:  handler,
+		Encoders: []Encoder{GzipEncoder{Level: gzip.DefaultCompression}, DeflateEncoder{Level: zlib.DefaultCompression}},
+		MinSize:  1024,
+	}
+}
+
+// Memilih encoder terbaik yang diterima client, nilai q=0 berarti ditolak
+func (middleware *CompressionMiddleware) negotiate(acceptEncoding string) Encoder {
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				value, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err == nil {
+					quality = value
+				}
+			}
+		}
+		accepted[name] = quality
+	}
+
+	var best Encoder
+	bestQuality := 0.0
+	for _, encoder := range middleware.Encoders {
+		quality, ok := accepted[encoder.Encoding()]
+		if !ok {
+			quality, ok = accepted["*"]
+		}
+		if ok && quality > bestQuality {
+			best = encoder
+			bestQuality = quality
+		}
+	}
+	return best
+}
+
+// Mengecek apakah Content-Type termasuk tipe yang sudah terkompresi
+func isIncompressible(contentType string) bool {
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Menambahkan nilai ke header Vary tanpa duplikasi
+func addVary(header http.Header, value string) {
+	for _, existing := range header.Values("Vary") {
+		for _, field := range strings.Split(existing, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
+
+// Melayani file .gz yang sudah ada di samping file asli (misal index.js.gz)
+// http.ServeContent dipakai agar Range request tetap bekerja pada versi terkompresi
+func (middleware *CompressionMiddleware) servePrecompressed(writer http.ResponseWriter, request *http.Request) bool {
+	if middleware.FileSystem == nil {
+		return false
+	}
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		return false
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+request.URL.Path), "/")
+	hash, err := computeStaticHash(middleware.FileSystem, name+".gz")
+	if err != nil {
+		return false
+	}
+	content, err := fs.ReadFile(middleware.FileSystem, name+".gz")
+	if err != nil {
+		return false
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("Content-Encoding", "gzip")
+	writer.Header().Set("ETag", hash.ETag)
+	http.ServeContent(writer, request, name, time.Time{}, bytes.NewReader(content))
+	return true
+}
+
+// Implementasi http.Handler
+func (middleware *CompressionMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	addVary(writer.Header(), "Accept-Encoding")
+
+	encoder := middleware.negotiate(request.Header.Get("Accept-Encoding"))
+	if encoder == nil {
+		middleware.Handler.ServeHTTP(writer, request)
+		return
+	}
+
+	if encoder.Encoding() == "gzip" && middleware.servePrecompressed(writer, request) {
+		return
+	}
+
+	// Range request dan HEAD tidak dikompres dinamis, agar byte range tetap sesuai file asli
+	if request.Header.Get("Range") != "" || request.Method == http.MethodHead {
+		middleware.Handler.ServeHTTP(writer, request)
+		return
+	}
+
+	compressWriter := &compressResponseWriter{
+		ResponseWriter: writer,
+		encoder:        encoder,
+		minSize:        middleware.MinSize,
+		status:         http.StatusOK,
+	}
+	defer compressWriter.Close()
+
+	middleware.Handler.ServeHTTP(compressWriter, request)
+}
+
+// ResponseWriter pembungkus yang menahan body sampai MinSize,
+// lalu memutuskan apakah response dikompres atau dikirim apa adanya
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoder    Encoder
+	minSize    int
+	status     int
+	buffer     []byte
+	decided    bool
+	compressor io.WriteCloser
+}
+
+func (writer *compressResponseWriter) WriteHeader(status int) {
+	if writer.decided {
+		return
+	}
+	if status < http.StatusOK {
+		// Informational response (1xx) langsung diteruskan
+		writer.ResponseWriter.WriteHeader(status)
+		return
+	}
+	writer.status = status
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		writer.decide(false)
+	}
+}
+
+func (writer *compressResponseWriter) Write(data []byte) (int, error) {
+	if !writer.decided {
+		writer.buffer = append(writer.buffer, data...)
+		if len(writer.buffer) < writer.minSize {
+			return len(data), nil
+		}
+		err := writer.decide(true)
+		return len(data), err
+	}
+	if writer.compressor != nil {
+		return writer.compressor.Write(data)
+	}
+	return writer.ResponseWriter.Write(data)
+}
+
+// Memutuskan kompresi, lalu mengirim header dan isi buffer
+func (writer *compressResponseWriter) decide(compress bool) error {
+	writer.decided = true
+	header := writer.Header()
+
+	if header.Get("Content-Type") == "" && len(writer.buffer) > 0 {
+		header.Set("Content-Type", http.DetectContentType(writer.buffer))
+	}
+	if header.Get("Content-Encoding") != "" || isIncompressible(header.Get("Content-Type")) {
+		compress = false
+	}
+
+	if compress {
+		compressor, err := writer.encoder.NewWriter(writer.ResponseWriter)
+		if err != nil {
+			compress = false
+		} else {
+			writer.compressor = compressor
+			header.Del("Content-Length")
+			header.Set("Content-Encoding", writer.encoder.Encoding())
+			// Strong ETag milik body asli tidak berlaku untuk byte hasil kompresi,
+			// dijadikan weak agar If-None-Match tetap cocok tetapi If-Range tidak
+			if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				header.Set("ETag", "W/"+etag)
+			}
+		}
+	}
+
+	writer.ResponseWriter.WriteHeader(writer.status)
+
+	buffer := writer.buffer
+	writer.buffer = nil
+	if len(buffer) == 0 {
+		return nil
+	}
+	if writer.compressor != nil {
+		_, err := writer.compressor.Write(buffer)
+		return err
+	}
+	_, err := writer.ResponseWriter.Write(buffer)
+	return err
+}
+
+// Flush memaksa data terkirim ke client, penting untuk streaming response
+func (writer *compressResponseWriter) Flush() {
+	if !writer.decided {
+		writer.decide(true)
+	}
+	if flusher, ok := writer.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+// Unwrap agar http.ResponseController dapat mengakses writer asli
+func (writer *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// Menutup kompresor, body kecil di bawah MinSize dikirim tanpa kompresi
+func (writer *compressResponseWriter) Close() error {
+	if !writer.decided {
+		writer.decide(false)
+	}
+	if writer.compressor != nil {
+		return writer.compressor.Close()
+	}
+	return nil
+}
+
+func TestCompressionMiddlewareDynamic(t *testing.T) {
+	data := map[string]interface{}{
+		"Title":   "Template Compression",
+		"Hobbies": strings.Split(strings.Repeat("Game,Watch,Code,", 50), ","),
+	}
+	original := new(bytes.Buffer)
+	myTemplates.ExecuteTemplate(original, "range.gohtml", data)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		myTemplates.ExecuteTemplate(writer, "range.gohtml", data)
+	})
+
+	middleware := NewCompressionMiddleware(mux)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/", nil)
+	request.Header.Set("Accept-Encoding", "deflate;q=0.5, gzip")
+	recorder := httptest.NewRecorder()
+
+	middleware.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	fmt.Println(response.Header.Get("Content-Encoding"), response.Header.Get("Vary"), response.Header.Get("Content-Type"))
+	if response.Header.Get("Content-Encoding") != "gzip" || response.Header.Get("Vary") != "Accept-Encoding" {
+		t.Errorf("unexpected headers %v", response.Header)
+	}
+
+	reader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(reader)
+	fmt.Println(len(body), "byte setelah dekompresi")
+	if !bytes.Equal(body, original.Bytes()) {
+		t.Errorf("decompressed body differs from the original (%d vs %d byte)", len(body), original.Len())
+	}
+}
+
+func TestCompressionMiddlewareETag(t *testing.T) {
+	fileSystem := fstest.MapFS{
+		"artikel.html": {Data: []byte(strings.Repeat("<p>Belajar Golang Web</p>\n", 100))},
+	}
+	static, err := NewStaticHandler(fileSystem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	middleware := NewCompressionMiddleware(static)
+
+	// Tanpa kompresi ETag tetap strong
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/artikel.html", nil)
+	recorder := httptest.NewRecorder()
+	middleware.ServeHTTP(recorder, request)
+	strong := recorder.Result().Header.Get("ETag")
+
+	// Body gzip mendapat weak ETag, karena byte-nya berbeda dari body asli
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:8080/artikel.html", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder = httptest.NewRecorder()
+	middleware.ServeHTTP(recorder, request)
+	response := recorder.Result()
+	weak := response.Header.Get("ETag")
+	fmt.Println(strong, weak, response.Header.Get("Content-Encoding"))
+	if response.Header.Get("Content-Encoding") != "gzip" || weak != "W/"+strong {
+		t.Errorf("expected weak ETag W/%s for gzip body, got %q", strong, weak)
+	}
+
+	// Weak ETag tetap bisa dipakai untuk revalidasi
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:8080/artikel.html", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	request.Header.Set("If-None-Match", weak)
+	recorder = httptest.NewRecorder()
+	middleware.ServeHTTP(recorder, request)
+	fmt.Println(recorder.Result().StatusCode)
+	if recorder.Result().StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", recorder.Result().StatusCode)
+	}
+}
+
+func TestCompressionMiddlewareDeflate(t *testing.T) {
+	body := strings.Repeat("Belajar Golang Web ", 200)
+	middleware := NewCompressionMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, body)
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/", nil)
+	request.Header.Set("Accept-Encoding", "deflate")
+	recorder := httptest.NewRecorder()
+
+	middleware.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	fmt.Println(response.Header.Get("Content-Encoding"))
+	if response.Header.Get("Content-Encoding") != "deflate" {
+		t.Fatalf("expected deflate, got %q", response.Header.Get("Content-Encoding"))
+	}
+
+	// Client HTTP membaca deflate sebagai stream zlib
+	reader, err := zlib.NewReader(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompressed, _ := io.ReadAll(reader)
+	if string(decompressed) != body {
+		t.Errorf("unexpected body after decompression (%d byte)", len(decompressed))
+	}
+}
+
+func TestCompressionMiddlewareSkip(t *testing.T) {
+	directory, _ := fs.Sub(resources, "resources")
+	middleware := NewCompressionMiddleware(http.FileServer(http.FS(directory)))
+
+	// File kecil (di bawah MinSize) dan file PNG tidak dikompres
+	for _, url := range []string{"/index.css", "/dottore.png"} {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+url, nil)
+		request.Header.Set("Accept-Encoding", "gzip")
+		recorder := httptest.NewRecorder()
+
+		middleware.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(url, response.StatusCode, response.Header.Get("Content-Type"), "encoding:", response.Header.Get("Content-Encoding"))
+
+		original, _ := fs.ReadFile(directory, strings.TrimPrefix(url, "/"))
+		if response.StatusCode != http.StatusOK || response.Header.Get("Content-Encoding") != "" {
+			t.Errorf("%s: expected uncompressed 200, got %d %q", url, response.StatusCode, response.Header.Get("Content-Encoding"))
+		}
+		if !bytes.Equal(body, original) {
+			t.Errorf("%s: body differs from the file", url)
+		}
+	}
+
+	// Pastikan index.css memang di bawah MinSize dan dottore.png cukup besar,
+	// sehingga yang diuji benar-benar batas ukuran dan pengecualian tipe gambar
+	css, _ := fs.ReadFile(directory, "index.css")
+	png, _ := fs.ReadFile(directory, "dottore.png")
+	if len(css) >= middleware.MinSize || len(png) < middleware.MinSize {
+		t.Errorf("unexpected fixture sizes: index.css %d, dottore.png %d, MinSize %d", len(css), len(png), middleware.MinSize)
+	}
+}
+
+func TestCompressionMiddlewarePrecompressed(t *testing.T) {
+	// Membuat file index.js beserta versi .gz di filesystem memory
+	script := []byte(strings.Repeat("function Hello() {}\n", 100))
+	compressed := new(bytes.Buffer)
+	gzipWriter := gzip.NewWriter(compressed)
+	gzipWriter.Write(script)
+	gzipWriter.Close()
+
+	fileSystem := fstest.MapFS{
+		"index.js":    {Data: script},
+		"index.js.gz": {Data: compressed.Bytes()},
+	}
+
+	middleware := NewCompressionMiddleware(http.FileServer(http.FS(fileSystem)))
+	middleware.FileSystem = fileSystem
+
+	// Range request diterapkan pada versi .gz
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/index.js", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	request.Header.Set("Range", "bytes=0-9")
+	recorder := httptest.NewRecorder()
+
+	middleware.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	fmt.Println(response.StatusCode, response.Header.Get("Content-Encoding"), response.Header.Get("Content-Range"), response.Header.Get("Content-Type"))
+
+	// Range dihitung terhadap byte file .gz, bukan file aslinya
+	if response.StatusCode != http.StatusPartialContent {
+		t.Errorf("expected 206, got %d", response.StatusCode)
+	}
+	expectedRange := fmt.Sprintf("bytes 0-9/%d", compressed.Len())
+	if response.Header.Get("Content-Range") != expectedRange {
+		t.Errorf("expected Content-Range %q, got %q", expectedRange, response.Header.Get("Content-Range"))
+	}
+	if response.Header.Get("Content-Encoding") != "gzip" || !strings.HasPrefix(response.Header.Get("Content-Type"), "text/javascript") {
+		t.Errorf("unexpected headers %v", response.Header)
+	}
+	if !bytes.Equal(body, compressed.Bytes()[:10]) {
+		t.Errorf("expected first 10 bytes of the .gz file, got %v", body)
+	}
+}
+
+func TestCompressionMiddlewareServer(t *testing.T) {
+	directory, _ := fs.Sub(resources, "resources")
+
+	static := NewCompressionMiddleware(http.FileServer(http.FS(directory)))
+	static.FileSystem = directory
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", static))
+	mux.HandleFunc("/", TemplateCaching)
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: NewCompressionMiddleware(mux),
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat middleware kompresi yang memilih encoder (gzip atau deflate) berdasarkan Accept-Encoding beserta nilai q, dengan interface Encoder sehingga encoder lain bisa ditambahkan. Body ditahan hingga mencapai MinSize sebelum diputuskan dikompres, tipe yang sudah terkompresi seperti PNG dilewati, dan header Vary selalu ditambahkan. ETag strong dari handler dijadikan weak ketika body dikompres dinamis, karena byte yang dikirim berbeda dari body aslinya. Untuk file statis, versi .gz yang sudah ada di samping file asli dilayani langsung melalui http.ServeContent sehingga Range request tetap berjalan dengan benar.
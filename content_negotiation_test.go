@@ -0,0 +1,394 @@
+package belajar_golang_web
+
+import (
+	"bytes"             // Buffer tempat response dirender sebelum dikirim
+	"context"           // Untuk menyimpan format dari suffix URL
+	"encoding/json"     // Untuk format JSON
+	"encoding/xml"      // Untuk format XML
+	"fmt"               // Untuk format teks dan output ke console
+	"io"                // Untuk membaca body response
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"path"              // Untuk membaca suffix format di URL
+	"reflect"           // Untuk mengecek apakah data bisa dijadikan XML
+	"strconv"           // Untuk membaca nilai q dan Content-Length
+	"strings"           // Untuk manipulasi string
+	"testing"           // Untuk unit testing
+)
+
+// Format response yang didukung beserta media type-nya
+var responseMediaTypes = map[string]string{
+	"html": "text/html",
+	"json": "application/json",
+	"xml":  "application/xml",
+	"text": "text/plain",
+}
+
+// Suffix URL yang memaksa format tertentu, misal /hello.json
+var responseFormatSuffixes = map[string]string{
+	".html": "html",
+	".json": "json",
+	".xml":  "xml",
+	".txt":  "text",
+}
+
+// Urutan format jika client menerima semuanya (Accept: */* atau tanpa Accept)
+var responseFormatOrder = []string{"html", "json", "xml", "text"}
+
+// Nilai yang dikembalikan handler, dirender ke format yang diminta client
+type View struct {
+	Status   int         // Default 200
+	Template string      // Template untuk HTML, kosong = HTML tidak tersedia
+	Data     interface{} // Data untuk template, JSON, XML, dan teks
+}
+
+// Responder merender View menggunakan template dari Templates
+type Responder struct {
+	Templates TemplateExecutor
+}
+
+// Responder bawaan menggunakan myTemplates
+var defaultResponder = &Responder{Templates: myTemplates}
+
+// Satu media range di header Accept, misal "application/json;q=0.9"
+type mediaRange struct {
+	Type    string
+	Subtype string
+	Quality float64
+}
+
+// Membaca header Accept
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, parameters, _ := strings.Cut(strings.TrimSpace(part), ";")
+		mainType, subtype, found := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+		if !found {
+			continue
+		}
+
+		quality := 1.0
+		for _, parameter := range strings.Split(parameters, ";") {
+			value, ok := strings.CutPrefix(strings.TrimSpace(parameter), "q=")
+			if !ok {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err == nil {
+				quality = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{Type: mainType, Subtype: subtype, Quality: quality})
+	}
+	return ranges
+}
+
+// Nilai q untuk satu media type, diambil dari media range yang paling spesifik
+// text/html lebih spesifik dari text/*, dan text/* lebih spesifik dari */*
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	mainType, subtype, _ := strings.Cut(mediaType, "/")
+
+	quality, specificity := 0.0, -1
+	for _, candidate := range ranges {
+		score := -1
+		switch {
+		case candidate.Type == mainType && candidate.Subtype == subtype:
+			score = 2
+		case candidate.Type == mainType && candidate.Subtype == "*":
+			score = 1
+		case candidate.Type == "*" && candidate.Subtype == "*":
+			score = 0
+		}
+		if score > specificity {
+			quality, specificity = candidate.Quality, score
+		}
+	}
+	return quality
+}
+
+// XML hanya ditawarkan untuk data yang menghasilkan satu root element
+// encoding/xml tidak bisa menulis map, dan slice menjadi beberapa root element
+// (atau error untuk []string), sehingga client mendapat 406 dan bukan 500
+func xmlEncodable(data interface{}) bool {
+	if data == nil {
+		return true
+	}
+	switch reflect.Indirect(reflect.ValueOf(data)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Chan, reflect.Func, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+// Format yang bisa dihasilkan untuk View ini
+func (view View) formats() []string {
+	var formats []string
+	for _, format := range responseFormatOrder {
+		switch format {
+		case "html":
+			if view.Template == "" {
+				continue
+			}
+		case "xml":
+			if !xmlEncodable(view.Data) {
+				continue
+			}
+		}
+		formats = append(formats, format)
+	}
+	return formats
+}
+
+// Memilih format berdasarkan suffix URL, lalu header Accept
+// Mengembalikan string kosong jika tidak ada format yang bisa diterima client (406)
+func negotiateFormat(request *http.Request, available []string) string {
+	if format, ok := request.Context().Value(formatSuffixKey{}).(string); ok {
+		if containsString(available, format) {
+			return format
+		}
+		return ""
+	}
+
+	header := request.Header.Get("Accept")
+	if header == "" {
+		return available[0]
+	}
+
+	ranges := parseAccept(header)
+	best, bestQuality := "", 0.0
+	for _, format := range available {
+		quality := acceptQuality(ranges, responseMediaTypes[format])
+		if quality > bestQuality {
+			best, bestQuality = format, quality
+		}
+	}
+	return best
+}
+
+// Merender data ke satu format
+func (responder *Responder) encode(buffer *bytes.Buffer, format string, view View) error {
+	switch format {
+	case "html":
+		return responder.Templates.ExecuteTemplate(buffer, view.Template, view.Data)
+	case "json":
+		return json.NewEncoder(buffer).Encode(view.Data)
+	case "xml":
+		buffer.WriteString(xml.Header)
+		return xml.NewEncoder(buffer).Encode(view.Data)
+	default:
+		if stringer, ok := view.Data.(fmt.Stringer); ok {
+			buffer.WriteString(stringer.String())
+		} else {
+			fmt.Fprint(buffer, view.Data)
+		}
+		return nil
+	}
+}
+
+// Menulis View dalam format yang diminta client
+func (responder *Responder) Respond(writer http.ResponseWriter, request *http.Request, view View) {
+	addVary(writer.Header(), "Accept")
+
+	available := view.formats()
+	format := negotiateFormat(request, available)
+	if format == "" {
+		var mediaTypes []string
+		for _, available := range available {
+			mediaTypes = append(mediaTypes, responseMediaTypes[available])
+		}
+		http.Error(writer, "Not Acceptable, available: "+strings.Join(mediaTypes, ", "), http.StatusNotAcceptable)
+		return
+	}
+
+	buffer := getRenderBuffer()
+	defer putRenderBuffer(buffer)
+
+	err := responder.encode(buffer, format, view)
+	if err != nil {
+		logRenderError(request, view.Template, err)
+		ErrorPage(writer, request, http.StatusInternalServerError, "")
+		return
+	}
+
+	status := view.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	writer.Header().Set("Content-Type", responseMediaTypes[format]+"; charset=utf-8")
+	writer.Header().Set("Content-Length", strconv.Itoa(buffer.Len()))
+	writer.WriteHeader(status)
+	buffer.WriteTo(writer)
+}
+
+// Menulis View menggunakan defaultResponder
+func Respond(writer http.ResponseWriter, request *http.Request, view View) {
+	defaultResponder.Respond(writer, request, view)
+}
+
+// Key context untuk format dari suffix URL
+type formatSuffixKey struct{}
+
+// Middleware suffix format: /hello.json diteruskan ke handler /hello dengan format json
+type FormatSuffixMiddleware struct {
+	Handler http.Handler
+}
+
+func (middleware *FormatSuffixMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	extension := path.Ext(request.URL.Path)
+	format, ok := responseFormatSuffixes[extension]
+	if !ok {
+		middleware.Handler.ServeHTTP(writer, request)
+		return
+	}
+
+	request = request.Clone(context.WithValue(request.Context(), formatSuffixKey{}, format))
+	request.URL.Path = strings.TrimSuffix(request.URL.Path, extension)
+	request.URL.RawPath = ""
+	middleware.Handler.ServeHTTP(writer, request)
+}
+
+// Data sapaan yang bisa ditampilkan sebagai HTML, JSON, XML, maupun teks
+type Greeting struct {
+	XMLName xml.Name `json:"-" xml:"greeting"`
+	Name    string   `json:"name" xml:"name"`
+	Message string   `json:"message" xml:"message"`
+}
+
+func (greeting Greeting) String() string {
+	return greeting.Message
+}
+
+// Versi SayHello yang hasilnya mengikuti permintaan client
+func SayHelloNegotiated(writer http.ResponseWriter, request *http.Request) {
+	name := request.URL.Query().Get("name")
+	message := "Hello"
+	if name != "" {
+		message = "Hello " + name
+	}
+	Respond(writer, request, View{Template: "greeting.gohtml", Data: Greeting{Name: name, Message: message}})
+}
+
+// Versi ResponseCode yang hasilnya mengikuti permintaan client
+func ResponseCodeNegotiated(writer http.ResponseWriter, request *http.Request) {
+	name := request.URL.Query().Get("name")
+	if name == "" {
+		Respond(writer, request, View{Status: http.StatusBadRequest, Template: "greeting.gohtml", Data: Greeting{Message: "name is empty"}})
+		return
+	}
+	Respond(writer, request, View{Template: "greeting.gohtml", Data: Greeting{Name: name, Message: "Hello " + name}})
+}
+
+// Versi MultipleQueryParameter, data berupa map sehingga XML tidak tersedia
+func MultipleQueryParameterNegotiated(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	Respond(writer, request, View{Data: map[string]string{
+		"first_name": query.Get("first_name"),
+		"last_name":  query.Get("last_name"),
+	}})
+}
+
+func negotiationMux() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", SayHelloNegotiated)
+	mux.HandleFunc("/response-code", ResponseCodeNegotiated)
+	mux.HandleFunc("/names", MultipleQueryParameterNegotiated)
+	return &FormatSuffixMiddleware{Handler: mux}
+}
+
+func TestContentNegotiation(t *testing.T) {
+	handler := negotiationMux()
+
+	tests := []struct {
+		target      string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"/hello?name=Hilmi", "", http.StatusOK, "text/html; charset=utf-8"},
+		{"/hello?name=Hilmi", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", http.StatusOK, "text/html; charset=utf-8"},
+		{"/hello?name=Hilmi", "application/json", http.StatusOK, "application/json; charset=utf-8"},
+		{"/hello?name=Hilmi", "application/xml", http.StatusOK, "application/xml; charset=utf-8"},
+		{"/hello?name=Hilmi", "text/*;q=0.5, text/plain", http.StatusOK, "text/plain; charset=utf-8"},
+		{"/hello?name=Hilmi", "image/png", http.StatusNotAcceptable, "text/plain; charset=utf-8"},
+		{"/hello?name=Hilmi", "application/json;q=0, */*", http.StatusOK, "text/html; charset=utf-8"},
+		{"/hello.json?name=Hilmi", "text/html", http.StatusOK, "application/json; charset=utf-8"},
+		{"/hello.txt?name=Hilmi", "", http.StatusOK, "text/plain; charset=utf-8"},
+		{"/response-code.json", "", http.StatusBadRequest, "application/json; charset=utf-8"},
+		{"/names?first_name=Hilmi&last_name=Yahya", "", http.StatusOK, "application/json; charset=utf-8"},
+		{"/names.xml", "", http.StatusNotAcceptable, "text/plain; charset=utf-8"},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+test.target, nil)
+		if test.accept != "" {
+			request.Header.Set("Accept", test.accept)
+		}
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(test.target, test.accept, "=>", response.StatusCode, response.Header.Get("Content-Type"))
+		fmt.Println(strings.TrimSpace(string(body)))
+
+		if response.StatusCode != test.status || response.Header.Get("Content-Type") != test.contentType {
+			t.Errorf("%s Accept=%q: expected %d %s, got %d %s", test.target, test.accept,
+				test.status, test.contentType, response.StatusCode, response.Header.Get("Content-Type"))
+		}
+		if response.Header.Get("Vary") != "Accept" {
+			t.Errorf("%s: expected Vary Accept, got %q", test.target, response.Header.Get("Vary"))
+		}
+	}
+}
+
+func TestContentNegotiationXMLSlice(t *testing.T) {
+	// Slice tidak bisa menjadi dokumen XML dengan satu root element
+	handler := &FormatSuffixMiddleware{Handler: http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		Respond(writer, request, View{Data: []Greeting{{Name: "Hilmi", Message: "Hello Hilmi"}, {Name: "Budi", Message: "Hello Budi"}}})
+	})}
+
+	tests := []struct {
+		target      string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"/greetings", "application/xml", http.StatusNotAcceptable, "text/plain; charset=utf-8"},
+		{"/greetings.xml", "", http.StatusNotAcceptable, "text/plain; charset=utf-8"},
+		{"/greetings", "application/xml, application/json;q=0.5", http.StatusOK, "application/json; charset=utf-8"},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+test.target, nil)
+		if test.accept != "" {
+			request.Header.Set("Accept", test.accept)
+		}
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(test.target, test.accept, "=>", response.StatusCode, strings.TrimSpace(string(body)))
+		if response.StatusCode != test.status || response.Header.Get("Content-Type") != test.contentType {
+			t.Errorf("%s Accept=%q: expected %d %s, got %d %s", test.target, test.accept,
+				test.status, test.contentType, response.StatusCode, response.Header.Get("Content-Type"))
+		}
+	}
+}
+
+func TestContentNegotiationServer(t *testing.T) {
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: negotiationMux(),
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat Responder yang memungkinkan handler mengembalikan satu nilai View lalu merendernya sebagai HTML (lewat template), JSON, XML, atau teks biasa sesuai header Accept beserta nilai q-nya, atau sesuai suffix URL seperti /hello.json. Response selalu memiliki Content-Type dengan charset, header Vary: Accept, dan status 406 Not Acceptable jika tidak ada format yang bisa diterima client. XML hanya ditawarkan untuk data yang menghasilkan satu root element, sehingga map dan slice tidak lagi berakhir dengan 500. SayHello, ResponseCode, dan MultipleQueryParameter dibuatkan versi yang memakai Responder.
@@ -0,0 +1,369 @@
+package belajar_golang_web
+
+import (
+	"encoding/json"     // Untuk varian listing dalam format JSON
+	"fmt"               // Untuk menampilkan output ke console
+	"io"                // Untuk membaca body response
+	"io/fs"             // Abstraksi filesystem (embed maupun disk)
+	"mime"              // Untuk menentukan icon berdasarkan tipe file
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"net/url"           // Untuk membuat URL entry yang aman
+	"path"              // Untuk pembersihan path dan pencocokan pola
+	"sort"              // Untuk mengurutkan isi folder
+	"strings"           // Untuk manipulasi string
+	"testing"           // Untuk unit testing
+	"testing/fstest"    // Filesystem di memory untuk test
+	"time"              // Untuk waktu modifikasi file
+)
+
+// Satu baris (file atau folder) pada halaman listing
+type DirectoryEntry struct {
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	IsDir       bool      `json:"is_dir"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+	Icon        string    `json:"-"`
+	SizeText    string    `json:"-"`
+	ModTimeText string    `json:"-"`
+}
+
+// Static file server dengan listing folder yang bisa dimatikan atau dirender lewat template
+type DirectoryListing struct {
+	FileSystem   fs.FS            // Sumber file: embed.FS atau os.DirFS
+	Handler      http.Handler     // Handler untuk file biasa, default http.FileServer
+	Disabled     bool             // true = listing folder dimatikan (404)
+	Exclude      []string         // Pola nama file atau path relatif yang disembunyikan, misal "*.tmp" atau "uploads/private/*"
+	Template     TemplateExecutor // Template listing, default myTemplates
+	TemplateName string           // Nama template listing
+}
+
+// Membuat DirectoryListing dengan template directory.gohtml
+func NewDirectoryListing(fileSystem fs.FS) *DirectoryListing {
+	return &DirectoryListing{
+		FileSystem:   fileSystem,
+		Handler:      http.FileServer(http.FS(fileSystem)),
+		Template:     myTemplates,
+		TemplateName: "directory.gohtml",
+	}
+}
+
+// Mengubah ukuran byte menjadi teks yang mudah dibaca, misal 251.7 KB
+func formatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	divisor, exponent := int64(unit), 0
+	for value := size / unit; value >= unit; value /= unit {
+		divisor *= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(divisor), "KMGTPE"[exponent])
+}
+
+// Icon sederhana berdasarkan tipe MIME file
+func directoryIcon(name string, isDir bool) string {
+	if isDir {
+		return "📁"
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "🖼️"
+	case strings.HasPrefix(contentType, "text/html"):
+		return "🌐"
+	case strings.HasPrefix(contentType, "text/"), strings.Contains(contentType, "javascript"):
+		return "📝"
+	case strings.HasPrefix(contentType, "video/"), strings.HasPrefix(contentType, "audio/"):
+		return "🎞️"
+	default:
+		return "📄"
+	}
+}
+
+// File tersembunyi (salah satu bagian path diawali titik) dan file yang cocok dengan pola Exclude
+// tidak ditampilkan maupun dilayani. Pola dicocokkan terhadap nama file dan path relatif lengkapnya
+func (listing *DirectoryListing) isExcluded(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") && segment != "." {
+			return true
+		}
+	}
+	for _, pattern := range listing.Exclude {
+		matchedBase, _ := path.Match(pattern, path.Base(name))
+		matchedPath, _ := path.Match(pattern, name)
+		if matchedBase || matchedPath {
+			return true
+		}
+	}
+	return false
+}
+
+// Membaca isi folder dan mengurutkannya sesuai parameter sort dan order
+func (listing *DirectoryListing) entries(name string, sortBy string, order string) ([]DirectoryEntry, error) {
+	dirEntries, err := fs.ReadDir(listing.FileSystem, name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []DirectoryEntry{}
+	for _, dirEntry := range dirEntries {
+		if listing.isExcluded(path.Join(name, dirEntry.Name())) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+
+		entryURL := url.URL{Path: dirEntry.Name()}
+		entry := DirectoryEntry{
+			Name:        dirEntry.Name(),
+			URL:         entryURL.String(),
+			IsDir:       dirEntry.IsDir(),
+			Size:        info.Size(),
+			ModTime:     info.ModTime(),
+			Icon:        directoryIcon(dirEntry.Name(), dirEntry.IsDir()),
+			SizeText:    formatFileSize(info.Size()),
+			ModTimeText: info.ModTime().Format("2006-01-02 15:04"),
+		}
+		if entry.IsDir {
+			entry.Name += "/"
+			entry.URL += "/"
+			entry.SizeText = "-"
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		left, right := entries[i], entries[j]
+		if order == "desc" {
+			left, right = right, left
+		}
+		switch sortBy {
+		case "size":
+			return left.Size < right.Size
+		case "date":
+			return left.ModTime.Before(right.ModTime)
+		default:
+			return left.Name < right.Name
+		}
+	})
+
+	return entries, nil
+}
+
+// Implementasi http.Handler
+func (listing *DirectoryListing) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	urlPath := path.Clean("/" + request.URL.Path)
+	name := strings.TrimPrefix(urlPath, "/")
+	if name == "" {
+		name = "."
+	}
+
+	// Kebijakan yang sama dengan listing: file yang disembunyikan juga tidak bisa diakses langsung
+	if listing.isExcluded(name) {
+		http.NotFound(writer, request)
+		return
+	}
+
+	info, err := fs.Stat(listing.FileSystem, name)
+	if err != nil || !info.IsDir() {
+		listing.Handler.ServeHTTP(writer, request)
+		return
+	}
+
+	// Folder yang memiliki index.html tetap dilayani oleh FileServer
+	_, err = fs.Stat(listing.FileSystem, path.Join(name, "index.html"))
+	if err == nil {
+		listing.Handler.ServeHTTP(writer, request)
+		return
+	}
+
+	if listing.Disabled {
+		http.NotFound(writer, request)
+		return
+	}
+
+	// Sama seperti FileServer, URL folder harus diakhiri "/" agar link relatif benar
+	if !strings.HasSuffix(request.URL.Path, "/") {
+		http.Redirect(writer, request, path.Base(urlPath)+"/", http.StatusMovedPermanently)
+		return
+	}
+
+	query := request.URL.Query()
+	sortBy := query.Get("sort")
+	if sortBy != "size" && sortBy != "date" {
+		sortBy = "name"
+	}
+	order := query.Get("order")
+	if order != "desc" {
+		order = "asc"
+	}
+
+	entries, err := listing.entries(name, sortBy, order)
+	if err != nil {
+		ErrorPage(writer, request, http.StatusInternalServerError, "")
+		return
+	}
+
+	// Varian JSON untuk script: ?format=json atau Accept: application/json
+	if query.Get("format") == "json" || strings.Contains(request.Header.Get("Accept"), "application/json") {
+		writer.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(writer).Encode(map[string]interface{}{
+			"path":    urlPath,
+			"entries": entries,
+		})
+		return
+	}
+
+	// Render menangani error template dengan halaman 500, bukan listing setengah jadi
+	Render(writer, request, listing.Template, listing.TemplateName, map[string]interface{}{
+		"Title":   "Index of " + urlPath,
+		"Path":    urlPath,
+		"Entries": entries,
+		"Sort":    sortBy,
+		"Order":   order,
+	})
+}
+
+// Filesystem contoh untuk test, berisi folder tanpa index.html
+var directoryListingTestFS = fstest.MapFS{
+	"uploads/a.png":       {Data: make([]byte, 2048), ModTime: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+	"uploads/b.css":       {Data: []byte(".b {}"), ModTime: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)},
+	"uploads/.secret":     {Data: []byte("rahasia"), ModTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	"uploads/draft.tmp":   {Data: []byte("draft"), ModTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	"uploads/docs/c.html": {Data: []byte("<h1>C</h1>"), ModTime: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC)},
+}
+
+func TestDirectoryListingTemplate(t *testing.T) {
+	listing := NewDirectoryListing(directoryListingTestFS)
+	listing.Exclude = []string{"*.tmp"}
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/uploads/?sort=size&order=desc", nil)
+	recorder := httptest.NewRecorder()
+
+	listing.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	fmt.Println(string(body))
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", response.StatusCode)
+	}
+	html := string(body)
+	if strings.Contains(html, ".secret") || strings.Contains(html, "draft.tmp") {
+		t.Error("hidden or excluded file is listed")
+	}
+	// Urut berdasarkan ukuran dari yang terbesar
+	if !(strings.Index(html, "a.png") < strings.Index(html, "b.css") && strings.Index(html, "a.png") >= 0) {
+		t.Error("entries are not sorted by size descending")
+	}
+}
+
+func TestDirectoryListingJSON(t *testing.T) {
+	listing := NewDirectoryListing(directoryListingTestFS)
+	listing.Exclude = []string{"*.tmp"}
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/uploads/?format=json&sort=date", nil)
+	recorder := httptest.NewRecorder()
+
+	listing.ServeHTTP(recorder, request)
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+
+	var result struct {
+		Path    string           `json:"path"`
+		Entries []DirectoryEntry `json:"entries"`
+	}
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entry := range result.Entries {
+		names = append(names, entry.Name)
+	}
+	// Folder docs dibuat implisit oleh MapFS sehingga waktu modifikasinya nol dan berada paling awal
+	if result.Path != "/uploads" || strings.Join(names, ",") != "docs/,a.png,b.css" {
+		t.Errorf("unexpected listing %s %v", result.Path, names)
+	}
+}
+
+func TestDirectoryListingDisabled(t *testing.T) {
+	listing := NewDirectoryListing(directoryListingTestFS)
+	listing.Disabled = true
+
+	// Folder mendapat 404, file tetap bisa diakses
+	tests := map[string]int{
+		"/uploads/":      http.StatusNotFound,
+		"/uploads/b.css": http.StatusOK,
+	}
+	for target, status := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+target, nil)
+		recorder := httptest.NewRecorder()
+
+		listing.ServeHTTP(recorder, request)
+
+		fmt.Println(target, recorder.Result().StatusCode)
+		if recorder.Result().StatusCode != status {
+			t.Errorf("%s: expected %d, got %d", target, status, recorder.Result().StatusCode)
+		}
+	}
+}
+
+func TestDirectoryListingExcludedFiles(t *testing.T) {
+	listing := NewDirectoryListing(directoryListingTestFS)
+	listing.Exclude = []string{"*.tmp", "uploads/docs/*"}
+
+	// File yang tidak tampil di listing juga tidak bisa diakses langsung
+	tests := []struct {
+		target string
+		status int
+	}{
+		{"/uploads/.secret", http.StatusNotFound},
+		{"/uploads/draft.tmp", http.StatusNotFound},
+		{"/uploads/docs/c.html", http.StatusNotFound},
+		{"/uploads/b.css", http.StatusOK},
+		{"/uploads/docs/", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+test.target, nil)
+		recorder := httptest.NewRecorder()
+
+		listing.ServeHTTP(recorder, request)
+
+		body, _ := io.ReadAll(recorder.Result().Body)
+		fmt.Println(test.target, recorder.Result().StatusCode)
+		if recorder.Result().StatusCode != test.status {
+			t.Errorf("%s: expected %d, got %d", test.target, test.status, recorder.Result().StatusCode)
+		}
+		if test.target == "/uploads/docs/" && strings.Contains(string(body), "c.html") {
+			t.Error("excluded path is listed")
+		}
+	}
+}
+
+func TestDirectoryListingServer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", NewDirectoryListing(directoryListingTestFS)))
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: mux,
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini mengganti listing folder bawaan http.FileServer yang polos dengan listing yang dapat dimatikan sepenuhnya atau dirender melalui template directory.gohtml, lengkap dengan ukuran file, waktu modifikasi, icon berdasarkan tipe MIME, serta pengurutan berdasarkan nama, ukuran, atau tanggal melalui query parameter. Tersedia juga varian JSON untuk kebutuhan script, dan file tersembunyi maupun file yang cocok dengan pola Exclude tidak pernah ditampilkan dan dibalas 404 jika diminta langsung.
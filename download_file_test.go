@@ -1,8 +1,8 @@
 package belajar_golang_web
 
 import (
-	"fmt"        // Untuk menulis response teks ke client
 	"net/http"   // Package utama untuk HTTP server dan request handling
+	"os"         // Untuk mengecek keberadaan file
 	"testing"    // Package testing untuk menjalankan fungsi Test
 )
 
@@ -11,11 +11,19 @@ func DownloadFile(writer http.ResponseWriter, request *http.Request) {
 	// Mengambil parameter query ?file= dari URL
 	file := request.URL.Query().Get("file")
 
-	// Validasi jika parameter file kosong
-	if file == "" {
-		writer.WriteHeader(http.StatusBadRequest) // Set status code 400
-		fmt.Fprint(writer, "Bad Request")          // Tulis pesan error
-		return                                    // Hentikan eksekusi handler
+	// Validasi jika parameter file kosong atau mencoba keluar dari folder resources
+	path, ok := safeResourcePath(file)
+	if !ok {
+		// Error 400 dalam format Problem Details (JSON untuk API, HTML untuk browser)
+		WriteProblem(writer, request, NewProblem(http.StatusBadRequest, "parameter file kosong atau tidak valid"))
+		return // Hentikan eksekusi handler
+	}
+
+	// File yang tidak ada dipetakan ErrorRegistry menjadi 404
+	_, err := os.Stat(path)
+	if err != nil {
+		WriteProblem(writer, request, err)
+		return
 	}
 
 	// Menambahkan header agar browser menganggap response sebagai file download
@@ -25,7 +33,7 @@ func DownloadFile(writer http.ResponseWriter, request *http.Request) {
 	)
 
 	// Mengirimkan file dari folder resources ke client
-	http.ServeFile(writer, request, "./resources/"+file)
+	http.ServeFile(writer, request, path)
 }
 
 // Test untuk menjalankan HTTP server secara manual
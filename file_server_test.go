@@ -2,7 +2,6 @@ package belajar_golang_web // Nama package, biasanya disesuaikan dengan folder a
 
 import (
 	"embed"      // Package untuk menyematkan file/folder ke dalam binary Go
-	"io/fs"      // Package untuk bekerja dengan filesystem abstraction
 	"net/http"   // Package standar untuk membuat web server HTTP
 	"testing"    // Package untuk membuat unit test di Go
 )
@@ -39,8 +38,9 @@ func TestFileServer(t *testing.T) {
 var resources embed.FS
 
 func TestFileServerGolangEmbed(t *testing.T) {
-	// Mengambil sub-folder "resources" dari embedded filesystem
-	directory, _ := fs.Sub(resources, "resources")
+	// Mengambil sub-folder "resources" dari embedded filesystem lewat overlay,
+	// sehingga file di $OVERLAY_DIR/resources bisa menimpa file embed
+	directory := overlayResources
 
 	// Membuat file server dari filesystem hasil embed
 	fileServer := http.FileServer(http.FS(directory))
@@ -9,16 +9,21 @@ import (
 	"testing"
 )
 
-func FormPost(writer http.ResponseWriter, request *http.Request) {
+func FormPost(writer http.ResponseWriter, request *http.Request) error {
 	err := request.ParseForm()
 	if err != nil {
-		panic(err)
+		return NewProblem(http.StatusBadRequest, err.Error())
 	}
 
-	firstName := request.PostForm.Get("first_name")
-	lastName := request.PostForm.Get("last_name")
+	// Field dan aturannya dibaca dari tag FormPostRequest, sama dengan dokumen OpenAPI
+	var form FormPostRequest
+	err = DecodeValues(request.PostForm, "form", &form)
+	if err != nil {
+		return err
+	}
 
-	fmt.Fprintf(writer, "Hello %s %s", firstName, lastName)
+	fmt.Fprintf(writer, "Hello %s %s", form.FirstName, form.LastName)
+	return nil
 }
 
 func TestFormPost(t *testing.T) {
@@ -28,10 +33,32 @@ func TestFormPost(t *testing.T) {
 
 	recoder := httptest.NewRecorder()
 
-	FormPost(recoder, request)
+	Handle(FormPost).ServeHTTP(recoder, request)
 
 	response := recoder.Result()
 	body, _ := io.ReadAll(response.Body)
 
 	fmt.Println(string(body))
+	if response.StatusCode != http.StatusOK || string(body) != "Hello Hilmi Yahya" {
+		t.Errorf("unexpected response %d %q", response.StatusCode, body)
+	}
+}
+
+func TestFormPostValidation(t *testing.T) {
+	// first_name wajib diisi sesuai tag validate di FormPostRequest
+	requestBody := strings.NewReader("last_name=Yahya")
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:8080", requestBody)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	recoder := httptest.NewRecorder()
+
+	Handle(FormPost).ServeHTTP(recoder, request)
+
+	response := recoder.Result()
+	body, _ := io.ReadAll(response.Body)
+
+	fmt.Println(response.StatusCode, string(body))
+	if response.StatusCode != http.StatusUnprocessableEntity || !strings.Contains(string(body), "first_name") {
+		t.Errorf("expected 422 for first_name, got %d %q", response.StatusCode, body)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,354 @@
+package belajar_golang_web
+
+import (
+	"encoding/json"     // Untuk decode dan encode JSON
+	"errors"            // Untuk errors.As
+	"fmt"               // Untuk pesan error dan output ke console
+	"io"                // Untuk io.EOF dan membaca body response
+	"iter"              // Untuk sumber data streaming
+	"mime"              // Untuk membaca Content-Type
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"strconv"           // Untuk Content-Length dan query di contoh
+	"strings"           // Untuk manipulasi string
+	"testing"           // Untuk unit testing
+)
+
+// Batas ukuran body JSON default (1 MB)
+const maxJSONBodySize = 1 << 20
+
+// Jumlah item streaming sebelum response di-flush ke client
+const jsonStreamFlushEvery = 100
+
+// Data yang bisa memvalidasi dirinya sendiri setelah di-decode
+type Validator interface {
+	Validate() error
+}
+
+// Bentuk response JSON yang konsisten: {"data": ..., "meta": ...}
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta,omitempty"`
+}
+
+// Mengecek Content-Type application/json atau turunannya (misal application/merge-patch+json)
+func isJSONContentType(header string) bool {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Membaca body JSON ke destination dengan aturan ketat:
+// Content-Type wajib JSON, ukuran dibatasi, field yang tidak dikenal ditolak,
+// hanya boleh satu nilai JSON, lalu Validate dipanggil jika destination adalah Validator
+// Error yang dikembalikan sudah berupa Problem atau ValidationErrors, siap untuk WriteProblem
+func DecodeJSON(writer http.ResponseWriter, request *http.Request, destination interface{}) error {
+	if !isJSONContentType(request.Header.Get("Content-Type")) {
+		return NewProblem(http.StatusUnsupportedMediaType, "Content-Type harus application/json")
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxJSONBodySize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(destination)
+	if err != nil {
+		return jsonDecodeError(err)
+	}
+
+	// Body seperti {"a":1}{"b":2} ditolak
+	var extra json.RawMessage
+	err = decoder.Decode(&extra)
+	if err != io.EOF {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return err
+		}
+		return NewProblem(http.StatusBadRequest, "body harus berisi satu nilai JSON saja")
+	}
+
+	if validator, ok := destination.(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+// Mengubah error dari encoding/json menjadi pesan yang jelas untuk client
+func jsonDecodeError(err error) error {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	var maxBytesError *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesError):
+		return err // Dipetakan ErrorRegistry menjadi 413
+	case errors.As(err, &syntaxError):
+		return NewProblem(http.StatusBadRequest, fmt.Sprintf("JSON tidak valid pada byte ke-%d", syntaxError.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return NewProblem(http.StatusBadRequest, "JSON tidak lengkap")
+	case errors.Is(err, io.EOF):
+		return NewProblem(http.StatusBadRequest, "body tidak boleh kosong")
+	case errors.As(err, &typeError):
+		field := typeError.Field
+		if field == "" {
+			return NewProblem(http.StatusBadRequest, fmt.Sprintf("body harus bertipe %s", typeError.Type))
+		}
+		return ValidationErrors{{Field: field, Message: fmt.Sprintf("harus bertipe %s, bukan %s", typeError.Type, typeError.Value)}}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return ValidationErrors{{Field: field, Message: "field tidak dikenal"}}
+	default:
+		return NewProblem(http.StatusBadRequest, err.Error())
+	}
+}
+
+// Menulis data dalam Envelope, ?pretty membuat JSON lebih mudah dibaca
+func WriteJSON(writer http.ResponseWriter, request *http.Request, status int, data interface{}) {
+	WriteJSONEnvelope(writer, request, status, Envelope{Data: data})
+}
+
+// Menulis Envelope lengkap, misal dengan meta pagination
+func WriteJSONEnvelope(writer http.ResponseWriter, request *http.Request, status int, envelope Envelope) {
+	buffer := getRenderBuffer()
+	defer putRenderBuffer(buffer)
+
+	encoder := json.NewEncoder(buffer)
+	if _, pretty := request.URL.Query()["pretty"]; pretty {
+		encoder.SetIndent("", "  ")
+	}
+	err := encoder.Encode(envelope)
+	if err != nil {
+		WriteProblem(writer, request, fmt.Errorf("encode JSON: %w", err))
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	writer.Header().Set("Content-Length", strconv.Itoa(buffer.Len()))
+	writer.WriteHeader(status)
+	buffer.WriteTo(writer)
+}
+
+// Menulis array besar tanpa menampung semuanya di memory: {"data":[item,item,...]}
+// Error di tengah streaming dikembalikan ke AppHandler karena status sudah terkirim
+func StreamJSON[T any](writer http.ResponseWriter, request *http.Request, items iter.Seq2[T, error]) error {
+	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	writer.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(writer)
+
+	_, err := io.WriteString(writer, `{"data":[`)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for item, err := range items {
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			io.WriteString(writer, ",")
+		}
+		_, err = writer.Write(data)
+		if err != nil {
+			return err
+		}
+		count++
+		if count%jsonStreamFlushEvery == 0 {
+			controller.Flush()
+		}
+	}
+
+	_, err = io.WriteString(writer, "]}\n")
+	return err
+}
+
+// Contoh body request, aturan validasi ditulis di tag validate
+type CreateUserRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Email string `json:"email" validate:"required,format=email"`
+	Age   int    `json:"age" validate:"min=0,max=150"`
+}
+
+func (user CreateUserRequest) Validate() error {
+	return ValidateStruct(user)
+}
+
+// Contoh AppHandler yang membaca dan menulis JSON
+func CreateUser(writer http.ResponseWriter, request *http.Request) error {
+	var user CreateUserRequest
+	err := DecodeJSON(writer, request, &user)
+	if err != nil {
+		return err
+	}
+
+	WriteJSON(writer, request, http.StatusCreated, user)
+	return nil
+}
+
+// Contoh streaming: /numbers?count=1000
+func ListNumbers(writer http.ResponseWriter, request *http.Request) error {
+	// Aturan count (wajib, minimal 0) dibaca dari tag NumbersQuery, sama dengan dokumen OpenAPI
+	var query NumbersQuery
+	err := DecodeValues(request.URL.Query(), "query", &query)
+	if err != nil {
+		return err
+	}
+	count := *query.Count
+
+	return StreamJSON(writer, request, func(yield func(map[string]int, error) bool) {
+		for number := 1; number <= count; number++ {
+			if !yield(map[string]int{"number": number, "square": number * number}, nil) {
+				return
+			}
+		}
+	})
+}
+
+func jsonMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("POST /users", Handle(CreateUser))
+	mux.Handle("GET /numbers", Handle(ListNumbers))
+	return mux
+}
+
+func TestDecodeJSON(t *testing.T) {
+	mux := jsonMux()
+
+	tests := []struct {
+		body        string
+		contentType string
+		status      int
+	}{
+		{`{"name": "Hilmi", "email": "hilmi@example.com", "age": 20}`, "application/json", http.StatusCreated},
+		{`{"name": "Hilmi", "email": "hilmi@example.com", "age": 20}`, "text/plain", http.StatusUnsupportedMediaType},
+		{`{"name": "Hilmi", "email": }`, "application/json; charset=utf-8", http.StatusBadRequest},
+		{`{"name": "Hilmi"`, "application/json", http.StatusBadRequest},
+		{``, "application/json", http.StatusBadRequest},
+		{`{"name": "Hilmi", "email": "hilmi@example.com", "age": "dua puluh"}`, "application/json", http.StatusUnprocessableEntity},
+		{`{"name": "Hilmi", "email": "hilmi@example.com", "role": "admin"}`, "application/json", http.StatusUnprocessableEntity},
+		{`{"name": "", "email": "bukan-email", "age": 200}`, "application/json", http.StatusUnprocessableEntity},
+		{`{"name": "Hilmi", "email": "hilmi@example.com"}{"name": "Budi"}`, "application/json", http.StatusBadRequest},
+		{`[1, 2, 3]`, "application/json", http.StatusBadRequest},
+		{`{"name": "` + strings.Repeat("a", maxJSONBodySize) + `"}`, "application/json", http.StatusRequestEntityTooLarge},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodPost, "http://localhost:8080/users", strings.NewReader(test.body))
+		request.Header.Set("Content-Type", test.contentType)
+		recorder := httptest.NewRecorder()
+
+		mux.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(response.StatusCode, strings.TrimSpace(string(body)))
+
+		if response.StatusCode != test.status {
+			t.Errorf("%.60s: expected %d, got %d", test.body, test.status, response.StatusCode)
+		}
+	}
+}
+
+func TestWriteJSONPretty(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/?pretty", nil)
+	recorder := httptest.NewRecorder()
+
+	WriteJSONEnvelope(recorder, request, http.StatusOK, Envelope{
+		Data: []string{"Game", "Watch", "Code"},
+		Meta: map[string]int{"total": 3},
+	})
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	fmt.Println(response.Header.Get("Content-Type"), response.Header.Get("Content-Length"))
+	fmt.Println(string(body))
+
+	expected := "{\n  \"data\": [\n    \"Game\",\n    \"Watch\",\n    \"Code\"\n  ],\n  \"meta\": {\n    \"total\": 3\n  }\n}\n"
+	if string(body) != expected {
+		t.Errorf("expected indented JSON, got %q", body)
+	}
+	if response.Header.Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Errorf("unexpected Content-Type %q", response.Header.Get("Content-Type"))
+	}
+	if response.Header.Get("Content-Length") != strconv.Itoa(len(body)) {
+		t.Errorf("Content-Length %q does not match body length %d", response.Header.Get("Content-Length"), len(body))
+	}
+
+	var envelope struct {
+		Meta struct {
+			Total int `json:"total"`
+		} `json:"meta"`
+	}
+	err := json.Unmarshal(body, &envelope)
+	if err != nil || envelope.Meta.Total != 3 {
+		t.Errorf("expected meta.total 3, got %d (%v)", envelope.Meta.Total, err)
+	}
+}
+
+func TestStreamJSON(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/numbers?count=5", nil)
+	recorder := httptest.NewRecorder()
+
+	jsonMux().ServeHTTP(recorder, request)
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+
+	var envelope struct {
+		Data []map[string]int `json:"data"`
+	}
+	err := json.Unmarshal(body, &envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envelope.Data) != 5 || envelope.Data[4]["square"] != 25 {
+		t.Errorf("unexpected stream result: %v", envelope.Data)
+	}
+}
+
+func TestListNumbersQuery(t *testing.T) {
+	// Aturan dari tag NumbersQuery: count wajib dikirim, berupa angka, dan minimal 0
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"count=0", http.StatusOK},
+		{"", http.StatusUnprocessableEntity},
+		{"count=-1", http.StatusUnprocessableEntity},
+		{"count=banyak", http.StatusUnprocessableEntity},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/numbers?"+test.query, nil)
+		recorder := httptest.NewRecorder()
+
+		jsonMux().ServeHTTP(recorder, request)
+
+		body, _ := io.ReadAll(recorder.Result().Body)
+		fmt.Println(test.query, recorder.Result().StatusCode, strings.TrimSpace(string(body)))
+		if recorder.Result().StatusCode != test.status {
+			t.Errorf("%q: expected %d, got %d", test.query, test.status, recorder.Result().StatusCode)
+		}
+	}
+}
+
+func TestJSONServer(t *testing.T) {
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: jsonMux(),
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini menambahkan helper JSON untuk request dan response. DecodeJSON mewajibkan Content-Type JSON, membatasi ukuran body, menolak field yang tidak dikenal maupun lebih dari satu nilai JSON, lalu menerjemahkan error encoding/json menjadi pesan yang jelas seperti posisi byte syntax error atau tipe field yang salah. Error tersebut berupa Problem atau ValidationErrors sehingga langsung ditangani oleh AppHandler dan WriteProblem. WriteJSON menulis response dalam Envelope yang konsisten dengan opsi ?pretty, dan StreamJSON menulis array besar secara bertahap tanpa menampung semuanya di memory.
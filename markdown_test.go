@@ -0,0 +1,1096 @@
+package belajar_golang_web
+
+import (
+	"crypto/sha256"     // Untuk hash isi markdown sebagai key cache
+	"fmt"               // Untuk menampilkan output ke console
+	"html"              // Untuk escape teks di dalam HTML
+	"html/template"     // Untuk tipe template.HTML
+	"io"                // Untuk membaca body response
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"net/url"           // Untuk query parameter di test
+	"regexp"            // Untuk mengenali jenis block markdown
+	"strings"           // Untuk manipulasi string
+	"sync"              // Untuk mengamankan cache hasil render
+	"testing"           // Untuk unit testing
+	"time"              // Untuk mengukur waktu render
+)
+
+// Pola block markdown
+var (
+	markdownHeadingPattern   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	markdownRulePattern      = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	markdownFencePattern     = regexp.MustCompile("^( {0,3})(```+|~~~+)[ \t]*([^`\\s]*)")
+	markdownListPattern      = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])([ \t]+|$)`)
+	markdownQuotePattern     = regexp.MustCompile(`^ {0,3}> ?`)
+	markdownSetextPattern    = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	markdownTableRowPattern  = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	markdownEntityPattern    = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	markdownAutolinkPattern  = regexp.MustCompile(`^<((?:https?|mailto):[^<>\s]+)>`)
+	markdownLanguagePattern  = regexp.MustCompile(`^[\w+#.-]+$`)
+	markdownPunctuationChars = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+)
+
+const (
+	// Kedalaman maksimal block (list, kutipan) dan inline (emphasis, link) bertingkat,
+	// lebih dalam dari ini ditampilkan sebagai teks agar waktu render tetap linear
+	maxMarkdownNesting = 16
+	// Kurung bertingkat di tujuan link dibatasi seperti cmark
+	maxLinkParenDepth = 32
+	// Ukuran maksimal markdown dari query ?body= di TemplateMarkdown
+	maxMarkdownBodySize = 64 << 10
+)
+
+// Renderer markdown ke HTML yang hasilnya selalu melewati sanitizer
+// Hasil render disimpan di cache berdasarkan hash isi markdown
+type MarkdownRenderer struct {
+	Policy     *SanitizerPolicy // Policy sanitizer untuk HTML hasil render
+	MaxEntries int              // Jumlah maksimal hasil render di cache
+
+	mutex sync.RWMutex
+	cache map[[sha256.Size]byte]template.HTML
+	order [][sha256.Size]byte // Key cache sesuai urutan masuk, entry terlama dibuang lebih dulu
+}
+
+// Policy untuk markdown: sama dengan UGCPolicy ditambah class bahasa di blok kode
+func MarkdownPolicy() *SanitizerPolicy {
+	policy := UGCPolicy()
+	policy.Tags["code"] = []string{"class"}
+	return policy
+}
+
+func NewMarkdownRenderer(policy *SanitizerPolicy) *MarkdownRenderer {
+	return &MarkdownRenderer{
+		Policy:     policy,
+		MaxEntries: 1000,
+		cache:      map[[sha256.Size]byte]template.HTML{},
+	}
+}
+
+// Renderer bawaan untuk function template "markdown"
+var defaultMarkdown = NewMarkdownRenderer(MarkdownPolicy())
+
+// Mengubah markdown menjadi HTML yang sudah dibersihkan
+func (renderer *MarkdownRenderer) Render(source string) template.HTML {
+	key := sha256.Sum256([]byte(source))
+
+	renderer.mutex.RLock()
+	result, ok := renderer.cache[key]
+	renderer.mutex.RUnlock()
+	if ok {
+		return result
+	}
+
+	result = renderer.Policy.SanitizeHTML(markdownToHTML(source))
+
+	renderer.mutex.Lock()
+	defer renderer.mutex.Unlock()
+	if _, ok := renderer.cache[key]; ok {
+		return result // Sudah dimasukkan oleh request lain
+	}
+	// Cache penuh, entry terlama dibuang satu per satu agar memory tidak terus bertambah
+	for len(renderer.cache) >= renderer.MaxEntries && len(renderer.order) > 0 {
+		delete(renderer.cache, renderer.order[0])
+		renderer.order = renderer.order[1:]
+	}
+	renderer.cache[key] = result
+	renderer.order = append(renderer.order, key)
+	return result
+}
+
+// Jumlah hasil render yang tersimpan di cache
+func (renderer *MarkdownRenderer) CacheSize() int {
+	renderer.mutex.RLock()
+	defer renderer.mutex.RUnlock()
+	return len(renderer.cache)
+}
+
+// {{markdown .Body}} menggunakan defaultMarkdown
+func renderMarkdown(source string) template.HTML {
+	return defaultMarkdown.Render(source)
+}
+
+// Merender markdown menjadi HTML mentah (belum disanitasi)
+func markdownToHTML(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\x00", "�")
+
+	builder := new(strings.Builder)
+	renderMarkdownBlocks(builder, strings.Split(source, "\n"), false, 0)
+	return builder.String()
+}
+
+func isBlankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// Menghapus indentasi sampai sejumlah kolom (tab dihitung 4 kolom)
+func removeIndent(line string, width int) string {
+	column := 0
+	for index, character := range line {
+		if column >= width {
+			return line[index:]
+		}
+		switch character {
+		case ' ':
+			column++
+		case '\t':
+			column += 4 - column%4
+		default:
+			return line[index:]
+		}
+	}
+	return ""
+}
+
+func indentWidth(line string) int {
+	column := 0
+	for _, character := range line {
+		switch character {
+		case ' ':
+			column++
+		case '\t':
+			column += 4 - column%4
+		default:
+			return column
+		}
+	}
+	return column
+}
+
+// Mengecek apakah baris memulai block baru sehingga paragraf sebelumnya berakhir
+func startsMarkdownBlock(line string) bool {
+	if markdownHeadingPattern.MatchString(line) || markdownRulePattern.MatchString(line) ||
+		markdownFencePattern.MatchString(line) || markdownQuotePattern.MatchString(line) {
+		return true
+	}
+	// List bernomor hanya memotong paragraf jika dimulai dari 1
+	match := markdownListPattern.FindStringSubmatch(line)
+	if match != nil && match[3] != "" {
+		marker := match[2]
+		return strings.ContainsAny(marker[:1], "-*+") || strings.HasPrefix(marker, "1")
+	}
+	return false
+}
+
+// Merender daftar baris menjadi block HTML
+// tight = true untuk isi list tanpa baris kosong, paragraf ditulis tanpa <p>
+// depth = kedalaman list/kutipan, di maxMarkdownNesting list dan kutipan tidak lagi dikenali
+// Mengembalikan true jika block terakhir adalah paragraf tanpa <p>
+func renderMarkdownBlocks(builder *strings.Builder, lines []string, tight bool, depth int) bool {
+	lastText := false
+	index := 0
+	for index < len(lines) {
+		lastText = false
+		line := lines[index]
+
+		if isBlankLine(line) {
+			index++
+			continue
+		}
+
+		// Fenced code block: ```go ... ```
+		if match := markdownFencePattern.FindStringSubmatch(line); match != nil {
+			fence, language := match[2], match[3]
+			indent := len(match[1])
+			index++
+
+			var code []string
+			for index < len(lines) {
+				trimmed := strings.TrimSpace(lines[index])
+				if strings.HasPrefix(trimmed, fence[:1]) && len(strings.TrimRight(trimmed, fence[:1])) == 0 && len(trimmed) >= len(fence) {
+					index++
+					break
+				}
+				code = append(code, removeIndent(lines[index], indent))
+				index++
+			}
+
+			builder.WriteString("<pre><code")
+			if markdownLanguagePattern.MatchString(language) {
+				builder.WriteString(` class="language-` + language + `"`)
+			}
+			builder.WriteString(">")
+			for _, codeLine := range code {
+				builder.WriteString(html.EscapeString(codeLine) + "\n")
+			}
+			builder.WriteString("</code></pre>\n")
+			continue
+		}
+
+		// Indented code block: baris diawali 4 spasi
+		if indentWidth(line) >= 4 {
+			var code []string
+			for index < len(lines) && (indentWidth(lines[index]) >= 4 || isBlankLine(lines[index])) {
+				code = append(code, removeIndent(lines[index], 4))
+				index++
+			}
+			// Baris kosong di akhir blok kode tidak ikut ditulis
+			for len(code) > 0 && isBlankLine(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+
+			builder.WriteString("<pre><code>")
+			for _, codeLine := range code {
+				builder.WriteString(html.EscapeString(codeLine) + "\n")
+			}
+			builder.WriteString("</code></pre>\n")
+			continue
+		}
+
+		// Horizontal rule: --- *** ___ (dicek sebelum list karena "* * *" juga mirip list)
+		if markdownRulePattern.MatchString(line) {
+			builder.WriteString("<hr>\n")
+			index++
+			continue
+		}
+
+		// ATX heading: # Judul
+		if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil {
+			level := len(match[1])
+			fmt.Fprintf(builder, "<h%d>%s</h%d>\n", level, renderMarkdownInline(strings.TrimSpace(match[2])), level)
+			index++
+			continue
+		}
+
+		// Blockquote: > kutipan
+		if depth < maxMarkdownNesting && markdownQuotePattern.MatchString(line) {
+			var quote []string
+			for index < len(lines) && !isBlankLine(lines[index]) {
+				current := lines[index]
+				if markdownQuotePattern.MatchString(current) {
+					quote = append(quote, markdownQuotePattern.ReplaceAllString(current, ""))
+				} else if len(quote) > 0 && !startsMarkdownBlock(current) {
+					quote = append(quote, current) // Lazy continuation
+				} else {
+					break
+				}
+				index++
+			}
+
+			builder.WriteString("<blockquote>\n")
+			renderMarkdownBlocks(builder, quote, false, depth+1)
+			builder.WriteString("</blockquote>\n")
+			continue
+		}
+
+		// List: - item, * item, 1. item
+		if depth < maxMarkdownNesting && markdownListPattern.MatchString(line) {
+			index = renderMarkdownList(builder, lines, index, depth)
+			continue
+		}
+
+		// Tabel: baris header diikuti baris pemisah |---|:---:|
+		if strings.Contains(line, "|") && index+1 < len(lines) && markdownTableRowPattern.MatchString(lines[index+1]) &&
+			strings.Contains(lines[index+1], "-") {
+			header := splitTableRow(line)
+			alignments := tableAlignments(splitTableRow(lines[index+1]))
+			if len(header) == len(alignments) {
+				index = renderMarkdownTable(builder, lines, index, header, alignments)
+				continue
+			}
+		}
+
+		// Paragraf: baris-baris sampai baris kosong atau block baru
+		paragraph := []string{strings.TrimSpace(line)}
+		index++
+		heading := 0
+		for index < len(lines) && !isBlankLine(lines[index]) {
+			// Setext heading: baris "===" atau "---" di bawah paragraf
+			if match := markdownSetextPattern.FindStringSubmatch(lines[index]); match != nil {
+				heading = 1
+				if match[1][0] == '-' {
+					heading = 2
+				}
+				index++
+				break
+			}
+			if startsMarkdownBlock(lines[index]) {
+				break
+			}
+			paragraph = append(paragraph, lines[index])
+			index++
+		}
+
+		text := renderParagraphLines(paragraph)
+		switch {
+		case heading > 0:
+			fmt.Fprintf(builder, "<h%d>%s</h%d>\n", heading, text, heading)
+		case tight:
+			builder.WriteString(text + "\n")
+			lastText = true
+		default:
+			builder.WriteString("<p>" + text + "</p>\n")
+		}
+	}
+	return lastText
+}
+
+// Menggabungkan baris paragraf, dua spasi atau "\" di akhir baris menjadi <br>
+func renderParagraphLines(lines []string) string {
+	var parts []string
+	for position, line := range lines {
+		line = strings.TrimLeft(line, " \t")
+		hardBreak := false
+		if position < len(lines)-1 {
+			if strings.HasSuffix(line, "  ") {
+				hardBreak = true
+			} else if strings.HasSuffix(line, "\\") {
+				hardBreak = true
+				line = strings.TrimSuffix(line, "\\")
+			}
+		}
+		text := renderMarkdownInline(strings.TrimRight(line, " \t"))
+		if hardBreak {
+			text += "<br>"
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Merender list beserta sub list, mengembalikan index baris setelah list
+func renderMarkdownList(builder *strings.Builder, lines []string, index int, depth int) int {
+	first := markdownListPattern.FindStringSubmatch(lines[index])
+	ordered := !strings.ContainsAny(first[2][:1], "-*+")
+	delimiter := first[2][len(first[2])-1:]
+
+	var items [][]string
+	loose := false
+	for index < len(lines) {
+		match := markdownListPattern.FindStringSubmatch(lines[index])
+		if match == nil {
+			break
+		}
+		markerOrdered := !strings.ContainsAny(match[2][:1], "-*+")
+		if markerOrdered != ordered || match[2][len(match[2])-1:] != delimiter {
+			break // Jenis marker berbeda memulai list baru
+		}
+
+		// Lebar isi item = indentasi + marker + spasi setelah marker
+		contentWidth := len(match[1]) + len(match[2]) + len(match[3])
+		if len(match[3]) > 4 || match[3] == "" {
+			contentWidth = len(match[1]) + len(match[2]) + 1
+		}
+		item := []string{removeIndent(lines[index][len(match[1])+len(match[2]):], 1)}
+		index++
+
+		for index < len(lines) {
+			current := lines[index]
+			if isBlankLine(current) {
+				// Baris kosong masih bagian item jika baris berikutnya menjorok
+				next := index + 1
+				for next < len(lines) && isBlankLine(lines[next]) {
+					next++
+				}
+				if next < len(lines) && indentWidth(lines[next]) >= contentWidth {
+					// Seluruh baris kosong ditambahkan sekaligus agar tidak dipindai ulang per baris
+					for index < next {
+						item = append(item, "")
+						index++
+					}
+					loose = true
+					continue
+				}
+				if next < len(lines) && markdownListPattern.MatchString(lines[next]) {
+					loose = true
+				}
+				break
+			}
+			if indentWidth(current) >= contentWidth {
+				item = append(item, removeIndent(current, contentWidth))
+			} else if !startsMarkdownBlock(current) && !markdownListPattern.MatchString(current) {
+				item = append(item, strings.TrimLeft(current, " \t")) // Lazy continuation
+			} else {
+				break
+			}
+			index++
+		}
+		items = append(items, item)
+
+		// Lewati baris kosong di antara item
+		next := index
+		for next < len(lines) && isBlankLine(lines[next]) {
+			next++
+		}
+		if next > index && next < len(lines) && markdownListPattern.MatchString(lines[next]) {
+			index = next
+		} else if next > index {
+			break
+		}
+	}
+
+	if ordered {
+		start := strings.TrimRight(first[2], ".)")
+		if strings.TrimLeft(start, "0") != "1" {
+			fmt.Fprintf(builder, "<ol start=\"%s\">\n", strings.TrimLeft(start, "0"))
+		} else {
+			builder.WriteString("<ol>\n")
+		}
+	} else {
+		builder.WriteString("<ul>\n")
+	}
+	for _, item := range items {
+		itemBuilder := new(strings.Builder)
+		lastText := renderMarkdownBlocks(itemBuilder, item, !loose, depth+1)
+
+		content := itemBuilder.String()
+		if lastText {
+			// Teks di akhir item langsung ditutup: <li>a</li>
+			content = strings.TrimSuffix(content, "\n")
+		}
+		if loose || strings.HasPrefix(content, "<ul>") || strings.HasPrefix(content, "<ol") {
+			content = "\n" + content
+		}
+		builder.WriteString("<li>" + content + "</li>\n")
+	}
+	if ordered {
+		builder.WriteString("</ol>\n")
+	} else {
+		builder.WriteString("</ul>\n")
+	}
+	return index
+}
+
+// Memecah baris tabel menjadi cell, "\|" tidak dianggap pemisah
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	var cells []string
+	current := new(strings.Builder)
+	for position := 0; position < len(line); position++ {
+		if line[position] == '\\' && position+1 < len(line) && line[position+1] == '|' {
+			current.WriteByte('|')
+			position++
+			continue
+		}
+		if line[position] == '|' {
+			cells = append(cells, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteByte(line[position])
+	}
+	return append(cells, strings.TrimSpace(current.String()))
+}
+
+// Perataan kolom dari baris pemisah: :--- kiri, :---: tengah, ---: kanan
+func tableAlignments(cells []string) []string {
+	alignments := make([]string, len(cells))
+	for position, cell := range cells {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			alignments[position] = "center"
+		case strings.HasSuffix(cell, ":"):
+			alignments[position] = "right"
+		case strings.HasPrefix(cell, ":"):
+			alignments[position] = "left"
+		}
+	}
+	return alignments
+}
+
+func writeTableRow(builder *strings.Builder, cellTag string, cells []string, alignments []string) {
+	builder.WriteString("<tr>\n")
+	for position, alignment := range alignments {
+		cell := ""
+		if position < len(cells) {
+			cell = cells[position]
+		}
+		if alignment != "" {
+			fmt.Fprintf(builder, "<%s align=\"%s\">%s</%s>\n", cellTag, alignment, renderMarkdownInline(cell), cellTag)
+		} else {
+			fmt.Fprintf(builder, "<%s>%s</%s>\n", cellTag, renderMarkdownInline(cell), cellTag)
+		}
+	}
+	builder.WriteString("</tr>\n")
+}
+
+// Merender tabel GFM, mengembalikan index baris setelah tabel
+func renderMarkdownTable(builder *strings.Builder, lines []string, index int, header []string, alignments []string) int {
+	builder.WriteString("<table>\n<thead>\n")
+	writeTableRow(builder, "th", header, alignments)
+	builder.WriteString("</thead>\n")
+	index += 2
+
+	bodyStarted := false
+	for index < len(lines) && !isBlankLine(lines[index]) && !startsMarkdownBlock(lines[index]) {
+		if !bodyStarted {
+			builder.WriteString("<tbody>\n")
+			bodyStarted = true
+		}
+		writeTableRow(builder, "td", splitTableRow(lines[index]), alignments)
+		index++
+	}
+	if bodyStarted {
+		builder.WriteString("</tbody>\n")
+	}
+	builder.WriteString("</table>\n")
+	return index
+}
+
+func isWordCharacter(character byte) bool {
+	return character >= 0x80 || (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z') || (character >= '0' && character <= '9')
+}
+
+// Mencari penutup emphasis (misal "**") yang tidak didahului spasi
+func findEmphasisClose(text string, start int, delimiter string) int {
+	unclosedRuns := map[int]bool{} // Panjang backtick yang sudah pasti tidak punya penutup
+	for position := start; position+len(delimiter) <= len(text); position++ {
+		switch text[position] {
+		case '\\':
+			position++
+			continue
+		case '`':
+			// Isi code span tidak diperiksa
+			run := countRun(text, position, '`')
+			end := -1
+			if !unclosedRuns[run] {
+				end = strings.Index(text[position+run:], strings.Repeat("`", run))
+			}
+			if end >= 0 {
+				position += run + end + run - 1
+			} else {
+				unclosedRuns[run] = true
+				position += run - 1
+			}
+			continue
+		}
+		if !strings.HasPrefix(text[position:], delimiter) || position == start {
+			continue
+		}
+		if text[position-1] == ' ' || text[position-1] == '\t' {
+			continue
+		}
+		after := position + len(delimiter)
+		if after < len(text) && text[after] == delimiter[0] {
+			// "***" tidak dipotong di tengah jika delimiter lebih pendek
+			if len(delimiter) == 1 {
+				position = after + countRun(text, after, delimiter[0]) - 1
+				continue
+			}
+		}
+		if delimiter[0] == '_' && after < len(text) && isWordCharacter(text[after]) {
+			continue // "_" di tengah kata bukan emphasis
+		}
+		return position
+	}
+	return -1
+}
+
+func countRun(text string, start int, character byte) int {
+	run := 0
+	for start+run < len(text) && text[start+run] == character {
+		run++
+	}
+	return run
+}
+
+// Memasangkan setiap "[" dengan "]" pasangannya dalam sekali jalan
+// "[" yang tidak punya pasangan tidak ada di map, sehingga "![![![..." tidak dipindai ulang
+func matchBrackets(text string) map[int]int {
+	pairs := map[int]int{}
+	var open []int
+	for position := 0; position < len(text); position++ {
+		switch text[position] {
+		case '\\':
+			position++
+		case '[':
+			open = append(open, position)
+		case ']':
+			if len(open) > 0 {
+				pairs[open[len(open)-1]] = position
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	return pairs
+}
+
+// Membaca tujuan link: (url "title"), mengembalikan url, title, dan posisi setelah ")"
+func parseLinkDestination(text string, start int) (string, string, int, bool) {
+	if start >= len(text) || text[start] != '(' {
+		return "", "", 0, false
+	}
+	position := start + 1
+	for position < len(text) && (text[position] == ' ' || text[position] == '\n') {
+		position++
+	}
+
+	destination := ""
+	if position < len(text) && text[position] == '<' {
+		// "<" dan baris baru tidak boleh ada di dalam <...>, pencarian berhenti di sana
+		end := strings.IndexAny(text[position+1:], "<>\n")
+		if end < 0 || text[position+1+end] != '>' {
+			return "", "", 0, false
+		}
+		destination = text[position+1 : position+1+end]
+		position += end + 2
+	} else {
+		depth := 0
+		begin := position
+		for position < len(text) && text[position] != ' ' && text[position] != '\n' {
+			if text[position] == '\\' && position+1 < len(text) {
+				position += 2
+				continue
+			}
+			if text[position] == '(' {
+				depth++
+				if depth > maxLinkParenDepth {
+					return "", "", 0, false
+				}
+			} else if text[position] == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+			position++
+		}
+		destination = text[begin:position]
+	}
+
+	for position < len(text) && (text[position] == ' ' || text[position] == '\n') {
+		position++
+	}
+
+	title := ""
+	if position < len(text) && strings.ContainsRune(`"'(`, rune(text[position])) {
+		closing := text[position]
+		end := strings.IndexByte(text[position+1:], closing)
+		if closing == '(' {
+			// Title (...) tidak boleh berisi "(" sehingga pencarian berhenti di kurung berikutnya
+			end = strings.IndexAny(text[position+1:], "()")
+			if end >= 0 && text[position+1+end] == '(' {
+				end = -1
+			}
+		}
+		if end < 0 {
+			return "", "", 0, false
+		}
+		title = text[position+1 : position+1+end]
+		position += end + 2
+		for position < len(text) && (text[position] == ' ' || text[position] == '\n') {
+			position++
+		}
+	}
+
+	if position >= len(text) || text[position] != ')' {
+		return "", "", 0, false
+	}
+	return unescapeMarkdown(destination), unescapeMarkdown(title), position + 1, true
+}
+
+// Menghapus backslash escape dan entity dari url/title
+func unescapeMarkdown(text string) string {
+	builder := new(strings.Builder)
+	for position := 0; position < len(text); position++ {
+		if text[position] == '\\' && position+1 < len(text) && strings.IndexByte(markdownPunctuationChars, text[position+1]) >= 0 {
+			position++
+		}
+		builder.WriteByte(text[position])
+	}
+	return html.UnescapeString(builder.String())
+}
+
+// Menghapus tag dari teks alt gambar, misal ![*foto*](x.png) -> alt="foto"
+func plainText(renderedHTML string) string {
+	builder := new(strings.Builder)
+	inTag := false
+	for _, character := range renderedHTML {
+		switch {
+		case character == '<':
+			inTag = true
+		case character == '>':
+			inTag = false
+		case !inTag:
+			builder.WriteRune(character)
+		}
+	}
+	return html.UnescapeString(builder.String())
+}
+
+// Merender elemen inline: code, emphasis, link, gambar, autolink, escape, dan entity
+// HTML mentah tidak diteruskan, melainkan ditampilkan sebagai teks
+func renderMarkdownInline(text string) string {
+	return renderMarkdownInlineDepth(text, 0)
+}
+
+// depth = kedalaman emphasis/link bertingkat, di maxMarkdownNesting isi ditampilkan sebagai teks
+func renderMarkdownInlineDepth(text string, depth int) string {
+	if depth >= maxMarkdownNesting {
+		return html.EscapeString(text)
+	}
+
+	builder := new(strings.Builder)
+	var brackets map[int]int // Pasangan "[" dan "]", dihitung saat "[" pertama ditemukan
+
+	// Posisi awal terkecil yang sudah pasti tidak punya penutup, per delimiter emphasis
+	// dan per panjang backtick, agar "_a _a _a ..." tidak dipindai sampai akhir untuk setiap pembuka
+	unclosedEmphasis := map[string]int{}
+	unclosedCode := map[int]bool{}
+	findClose := func(start int, delimiter string) int {
+		if failed, ok := unclosedEmphasis[delimiter]; ok && start >= failed {
+			return -1
+		}
+		end := findEmphasisClose(text, start, delimiter)
+		if end < 0 {
+			unclosedEmphasis[delimiter] = start
+		}
+		return end
+	}
+
+	for position := 0; position < len(text); position++ {
+		character := text[position]
+		switch character {
+		case '\\':
+			if position+1 < len(text) && strings.IndexByte(markdownPunctuationChars, text[position+1]) >= 0 {
+				builder.WriteString(html.EscapeString(text[position+1 : position+2]))
+				position++
+				continue
+			}
+
+		case '`':
+			run := countRun(text, position, '`')
+			closing := strings.Repeat("`", run)
+			search := position + run
+			if unclosedCode[run] {
+				search = -1
+			}
+			for search >= 0 {
+				end := strings.Index(text[search:], closing)
+				if end < 0 {
+					search = -1
+					break
+				}
+				// Penutup harus memiliki jumlah backtick yang sama persis
+				if countRun(text, search+end, '`') == run {
+					search += end
+					break
+				}
+				search += end + countRun(text, search+end, '`')
+			}
+			if search < 0 {
+				unclosedCode[run] = true
+				builder.WriteString(closing)
+				position += run - 1
+				continue
+			}
+			code := strings.ReplaceAll(text[position+run:search], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			builder.WriteString("<code>" + html.EscapeString(code) + "</code>")
+			position = search + run - 1
+			continue
+
+		case '*', '_', '~':
+			run := countRun(text, position, character)
+			if character == '~' && run != 2 {
+				break
+			}
+			if character == '_' && position > 0 && isWordCharacter(text[position-1]) {
+				builder.WriteString(text[position : position+run])
+				position += run - 1
+				continue
+			}
+			after := position + run
+			if after >= len(text) || text[after] == ' ' || text[after] == '\t' || text[after] == '\n' {
+				builder.WriteString(text[position : position+run])
+				position += run - 1
+				continue
+			}
+
+			// "**" strong, "*" em, "***" strong+em, "~~" del
+			delimiter := text[position : position+run]
+			if run > 3 {
+				break
+			}
+			end := findClose(after, delimiter)
+			if end < 0 && run >= 2 && character != '~' {
+				// Tidak ada penutup dengan panjang sama, coba delimiter lebih pendek
+				delimiter = delimiter[:1]
+				after = position + 1
+				end = findClose(after, delimiter)
+			}
+			if end < 0 {
+				builder.WriteString(html.EscapeString(delimiter))
+				position += len(delimiter) - 1
+				continue
+			}
+
+			inner := renderMarkdownInlineDepth(text[after:end], depth+1)
+			switch {
+			case character == '~':
+				builder.WriteString("<del>" + inner + "</del>")
+			case len(delimiter) == 3:
+				builder.WriteString("<em><strong>" + inner + "</strong></em>")
+			case len(delimiter) == 2:
+				builder.WriteString("<strong>" + inner + "</strong>")
+			default:
+				builder.WriteString("<em>" + inner + "</em>")
+			}
+			position = end + len(delimiter) - 1
+			continue
+
+		case '!', '[':
+			image := character == '!'
+			open := position
+			if image {
+				if position+1 >= len(text) || text[position+1] != '[' {
+					break
+				}
+				open++
+			}
+			if brackets == nil {
+				brackets = matchBrackets(text)
+			}
+			closeBracket, ok := brackets[open]
+			if !ok {
+				break
+			}
+			destination, title, next, ok := parseLinkDestination(text, closeBracket+1)
+			if !ok {
+				break
+			}
+
+			label := renderMarkdownInlineDepth(text[open+1:closeBracket], depth+1)
+			titleAttribute := ""
+			if title != "" {
+				titleAttribute = ` title="` + html.EscapeString(title) + `"`
+			}
+			if image {
+				fmt.Fprintf(builder, `<img src="%s" alt="%s"%s>`, html.EscapeString(destination), html.EscapeString(plainText(label)), titleAttribute)
+			} else {
+				fmt.Fprintf(builder, `<a href="%s"%s>%s</a>`, html.EscapeString(destination), titleAttribute, label)
+			}
+			position = next - 1
+			continue
+
+		case '<':
+			if match := markdownAutolinkPattern.FindStringSubmatch(text[position:]); match != nil {
+				link := html.EscapeString(match[1])
+				fmt.Fprintf(builder, `<a href="%s">%s</a>`, link, link)
+				position += len(match[0]) - 1
+				continue
+			}
+
+		case '&':
+			if entity := markdownEntityPattern.FindString(text[position:]); entity != "" {
+				builder.WriteString(html.EscapeString(html.UnescapeString(entity)))
+				position += len(entity) - 1
+				continue
+			}
+		}
+
+		builder.WriteString(html.EscapeString(text[position : position+1]))
+	}
+	return builder.String()
+}
+
+// Helper untuk handler: merender markdown ke dalam post.gohtml
+func RenderMarkdown(writer http.ResponseWriter, request *http.Request, title string, source string) {
+	Render(writer, request, myTemplates, "post.gohtml", map[string]interface{}{
+		"Title": title,
+		"Body":  defaultMarkdown.Render(source),
+	})
+}
+
+func TemplateMarkdown(writer http.ResponseWriter, request *http.Request) {
+	body := request.URL.Query().Get("body")
+	if len(body) > maxMarkdownBodySize {
+		ErrorPage(writer, request, http.StatusRequestEntityTooLarge, "Markdown terlalu besar")
+		return
+	}
+	RenderMarkdown(writer, request, "Template Markdown", body)
+}
+
+// Contoh artikel markdown
+const markdownSample = "# Belajar Golang Web\n\n" +
+	"Ini adalah **artikel** dengan *emphasis*, ~~coret~~, dan `kode inline`.\n" +
+	"Baris ini memiliki hard break  \nsetelahnya.\n\n" +
+	"## Daftar\n\n" +
+	"- Satu\n- Dua\n  - Dua A\n  - Dua B\n- Tiga\n\n" +
+	"1. Pertama\n2. Kedua\n\n" +
+	"> Kutipan dengan [link](https://golang.org \"Go\").\n\n" +
+	"```go\nfmt.Println(\"<Hello>\")\n```\n\n" +
+	"| Nama | Umur |\n|:-----|-----:|\n| Hilmi | 20 |\n| Budi | 30 |\n\n" +
+	"---\n\n" +
+	"![Logo *Go*](/static/logo.png) <https://example.com>\n"
+
+func TestMarkdownElements(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"# Judul", "<h1>Judul</h1>\n"},
+		{"### Judul ###", "<h3>Judul</h3>\n"},
+		{"Judul\n===", "<h1>Judul</h1>\n"},
+		{"Sub\n---", "<h2>Sub</h2>\n"},
+		{"Halo **tebal** dan *miring*", "<p>Halo <strong>tebal</strong> dan <em>miring</em></p>\n"},
+		{"snake_case_name", "<p>snake_case_name</p>\n"},
+		{"***keduanya***", "<p><em><strong>keduanya</strong></em></p>\n"},
+		{"`a < b`", "<p><code>a &lt; b</code></p>\n"},
+		{"``ada ` backtick``", "<p><code>ada ` backtick</code></p>\n"},
+		{`\*bukan miring\*`, "<p>*bukan miring*</p>\n"},
+		{"AT&amp;T &copy; &bukan", "<p>AT&amp;T © &amp;bukan</p>\n"},
+		{"- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"- a\n\n- b", "<ul>\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ul>\n"},
+		{"3. tiga\n4. empat", "<ol start=\"3\">\n<li>tiga</li>\n<li>empat</li>\n</ol>\n"},
+		{"- a\n  - b", "<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul>\n</li>\n</ul>\n"},
+		{"> kutipan", "<blockquote>\n<p>kutipan</p>\n</blockquote>\n"},
+		{"```\n<b>\n```", "<pre><code>&lt;b&gt;\n</code></pre>\n"},
+		{"    kode", "<pre><code>kode\n</code></pre>\n"},
+		{"***", "<hr>\n"},
+		{"[Go](https://go.dev)", "<p><a href=\"https://go.dev\">Go</a></p>\n"},
+		{"![alt *x*](a.png \"judul\")", "<p><img src=\"a.png\" alt=\"alt x\" title=\"judul\"></p>\n"},
+		{"<https://go.dev>", "<p><a href=\"https://go.dev\">https://go.dev</a></p>\n"},
+		{"<b>html</b>", "<p>&lt;b&gt;html&lt;/b&gt;</p>\n"},
+		{"a|b\n-|-\n1|2", "<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>1</td>\n<td>2</td>\n</tr>\n</tbody>\n</table>\n"},
+	}
+
+	for _, test := range tests {
+		result := markdownToHTML(test.source)
+		if result != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.source, test.expected, result)
+		}
+	}
+}
+
+func TestMarkdownSanitized(t *testing.T) {
+	tests := []string{
+		"[klik](javascript:alert(1))",
+		"[klik](  JaVaScRiPt:alert(1) )",
+		"![x](javascript:alert(1))",
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		"[x](https://example.com\" onclick=\"alert(1))",
+		"```\"><script>alert(1)</script>\n```",
+		"```go onclick=alert(1)\nx\n```",
+	}
+
+	for _, source := range tests {
+		result := string(defaultMarkdown.Render(source))
+		fmt.Printf("%s\n  => %s", source, result)
+
+		// Teks yang sudah di-escape tetap aman, yang dicek hanya tag dan atribut hasil render
+		lower := strings.ToLower(result)
+		if strings.Contains(lower, `="javascript:`) || strings.Contains(lower, "<script") || containsEventAttribute(lower) {
+			t.Errorf("%q menghasilkan HTML berbahaya: %q", source, result)
+		}
+	}
+}
+
+func TestMarkdownCache(t *testing.T) {
+	renderer := NewMarkdownRenderer(MarkdownPolicy())
+	renderer.MaxEntries = 2
+
+	first := renderer.Render(markdownSample)
+	second := renderer.Render(markdownSample)
+	if first != second || renderer.CacheSize() != 1 {
+		t.Errorf("expected 1 cache entry, got %d", renderer.CacheSize())
+	}
+
+	renderer.Render("a")
+	renderer.Render("b") // Cache penuh, hanya entry terlama (markdownSample) yang dibuang
+	fmt.Println("cache size:", renderer.CacheSize())
+	if renderer.CacheSize() != 2 {
+		t.Errorf("expected 2 cache entries, got %d", renderer.CacheSize())
+	}
+	if _, ok := renderer.cache[sha256.Sum256([]byte("a"))]; !ok {
+		t.Error("entry a should still be cached")
+	}
+	if _, ok := renderer.cache[sha256.Sum256([]byte(markdownSample))]; ok {
+		t.Error("oldest entry should have been evicted")
+	}
+}
+
+func TestMarkdownLinearTime(t *testing.T) {
+	// Input yang sebelumnya membuat parser kuadratik (pembuka tanpa penutup, list dan kutipan bertingkat)
+	tests := []string{
+		strings.Repeat("- ", 20000),
+		strings.Repeat("1. ", 20000),
+		strings.Repeat("> ", 20000),
+		strings.Repeat("_a ", 20000),
+		strings.Repeat("**a ", 15000),
+		strings.Repeat("![", 20000),
+		strings.Repeat("[a](", 15000),
+		strings.Repeat("[a](x (", 8000) + ")",
+		strings.Repeat("`a``", 15000),
+		"- a" + strings.Repeat("\n", 40000) + "  b",
+	}
+
+	for _, source := range tests {
+		start := time.Now()
+		NewMarkdownRenderer(MarkdownPolicy()).Render(source)
+		elapsed := time.Since(start)
+		fmt.Printf("%q... %d byte: %v\n", source[:8], len(source), elapsed)
+		if elapsed > 2*time.Second {
+			t.Errorf("%q...: render took %v", source[:8], elapsed)
+		}
+	}
+}
+
+func TestMarkdownNestingLimit(t *testing.T) {
+	result := markdownToHTML(strings.Repeat("> ", 20) + "teks")
+	if count := strings.Count(result, "<blockquote>"); count != maxMarkdownNesting {
+		t.Errorf("expected %d nested blockquotes, got %d", maxMarkdownNesting, count)
+	}
+	if !strings.Contains(result, "teks") {
+		t.Errorf("content lost: %q", result)
+	}
+}
+
+func TestMarkdownTemplateFunction(t *testing.T) {
+	tmpl := template.Must(template.New("MARKDOWN").Funcs(StandardFuncs()).Parse(`<article>{{markdown .}}</article>`))
+
+	builder := new(strings.Builder)
+	err := tmpl.Execute(builder, markdownSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(builder.String())
+}
+
+func TestTemplateMarkdown(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080?body="+url.QueryEscape(markdownSample), nil)
+	recorder := httptest.NewRecorder()
+
+	TemplateMarkdown(recorder, request)
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+}
+
+func TestTemplateMarkdownTooLarge(t *testing.T) {
+	source := strings.Repeat("a", maxMarkdownBodySize+1)
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080?body="+source, nil)
+	recorder := httptest.NewRecorder()
+
+	TemplateMarkdown(recorder, request)
+
+	response := recorder.Result()
+	fmt.Println(response.StatusCode)
+	if response.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", response.StatusCode)
+	}
+}
+
+func TestTemplateMarkdownServer(t *testing.T) {
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: http.HandlerFunc(TemplateMarkdown),
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini menambahkan renderer markdown (heading, list bertingkat, blok kode, kutipan, link, gambar, emphasis, dan tabel) yang hasilnya selalu melewati sanitizer sebelum ditandai template.HTML. HTML mentah di dalam markdown ditampilkan sebagai teks, dan link berbahaya seperti javascript: dibuang oleh MarkdownPolicy. Hasil render disimpan di cache berdasarkan hash sha256 isi markdown, tersedia sebagai function template "markdown" dan helper RenderMarkdown untuk handler yang menampilkan post.gohtml. Pencarian penutup emphasis, code span, dan kurung link diingat per render sehingga waktu render tetap linear, kedalaman block dan inline bertingkat dibatasi, cache membuang entry terlama satu per satu, dan ukuran ?body= di TemplateMarkdown dibatasi.
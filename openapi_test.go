@@ -0,0 +1,686 @@
+package belajar_golang_web
+
+import (
+	"encoding/json"     // Untuk menulis dokumen OpenAPI dalam JSON
+	"fmt"               // Untuk output ke console
+	"io"                // Untuk membaca body response
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"reflect"           // Untuk membuat schema dari tipe Go
+	"slices"            // Untuk mengurutkan key YAML
+	"strconv"           // Untuk status code dan nilai YAML
+	"strings"           // Untuk manipulasi string
+	"testing"           // Untuk unit testing
+	"time"              // Untuk schema date-time
+)
+
+// Parameter path, query, atau header pada satu route
+type ParameterDoc struct {
+	Name        string
+	In          string // "path", "query", atau "header"
+	Description string
+	Required    bool
+	Schema      map[string]interface{}
+}
+
+// Keterangan satu route untuk dokumen OpenAPI
+// Body, Response, dan Meta cukup diisi nilai kosong dari tipenya, misal CreateUserRequest{}
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Parameters  []ParameterDoc
+	Query       interface{} // Struct dengan tag query, diubah menjadi ParameterDoc
+	Form        interface{} // Struct dengan tag form, body application/x-www-form-urlencoded
+	Body        interface{} // Tipe body JSON
+	ContentType string      // Default application/json
+	Response    interface{} // Tipe field data pada Envelope, nil berarti tanpa body
+	Meta        interface{} // Tipe field meta pada Envelope
+	Status      int         // Status sukses, default 200
+	Errors      []int       // Status error yang mungkin, ditulis sebagai Problem Details
+}
+
+// Route yang sudah didaftarkan beserta keterangannya
+type apiRoute struct {
+	method string
+	path   string
+	doc    RouteDoc
+}
+
+// ServeMux yang juga mencatat keterangan setiap route,
+// lalu menyajikan /openapi.json, /openapi.yaml, dan halaman explorer di /docs
+type APIRouter struct {
+	Title       string
+	Version     string
+	Description string
+
+	mux    *http.ServeMux
+	routes []apiRoute
+}
+
+func NewAPIRouter(title string, version string) *APIRouter {
+	router := &APIRouter{Title: title, Version: version, mux: http.NewServeMux()}
+	router.mux.HandleFunc("GET /openapi.json", router.serveJSON)
+	router.mux.HandleFunc("GET /openapi.yaml", router.serveYAML)
+	router.mux.HandleFunc("GET /docs", serveAPIExplorer)
+	router.mux.HandleFunc("GET /docs/openapi.js", serveAPIExplorer)
+	return router
+}
+
+// Mendaftarkan handler dengan pola ServeMux, misal "GET /pages/{id}"
+func (router *APIRouter) Handle(pattern string, handler http.Handler, doc RouteDoc) {
+	router.mux.Handle(pattern, handler)
+
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		method, path = "", pattern
+	}
+	router.routes = append(router.routes, apiRoute{method: strings.ToLower(method), path: path, doc: doc})
+}
+
+func (router *APIRouter) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	router.mux.ServeHTTP(writer, request)
+}
+
+// Membuat dokumen OpenAPI 3.1 dari route yang sudah didaftarkan
+func (router *APIRouter) Document() map[string]interface{} {
+	builder := &schemaBuilder{components: map[string]interface{}{}}
+	problem := builder.schema(reflect.TypeOf(Problem{}))
+
+	paths := map[string]interface{}{}
+	for _, route := range router.routes {
+		// Route tanpa method dicatat sebagai GET
+		method := route.method
+		if method == "" {
+			method = "get"
+		}
+
+		item, ok := paths[openAPIPath(route.path)].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[openAPIPath(route.path)] = item
+		}
+		item[method] = builder.operation(route, problem)
+	}
+
+	info := map[string]interface{}{"title": router.Title, "version": router.Version}
+	if router.Description != "" {
+		info["description"] = router.Description
+	}
+	return map[string]interface{}{
+		"openapi":    "3.1.0",
+		"info":       info,
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": builder.components},
+	}
+}
+
+// Path OpenAPI tidak mengenal wildcard {name...} dan penanda akhir {$}
+func openAPIPath(path string) string {
+	path = strings.ReplaceAll(path, "{$}", "")
+	return strings.ReplaceAll(path, "...}", "}")
+}
+
+// Nama parameter dari path, misal /pages/{id} menjadi [id]
+func pathParameters(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && segment != "{$}" {
+			names = append(names, strings.TrimSuffix(segment[1:len(segment)-1], "..."))
+		}
+	}
+	return names
+}
+
+func (builder *schemaBuilder) operation(route apiRoute, problem map[string]interface{}) map[string]interface{} {
+	doc := route.doc
+	operation := map[string]interface{}{
+		"operationId": operationID(route),
+	}
+	if doc.Summary != "" {
+		operation["summary"] = doc.Summary
+	}
+	if doc.Description != "" {
+		operation["description"] = doc.Description
+	}
+	if len(doc.Tags) > 0 {
+		operation["tags"] = doc.Tags
+	}
+
+	var parameters []interface{}
+	for _, name := range pathParameters(route.path) {
+		parameters = append(parameters, map[string]interface{}{
+			"name": name, "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, parameter := range append(builder.queryParameters(doc.Query), doc.Parameters...) {
+		object := map[string]interface{}{"name": parameter.Name, "in": parameter.In, "schema": parameter.Schema}
+		if parameter.Required {
+			object["required"] = true
+		}
+		if parameter.Description != "" {
+			object["description"] = parameter.Description
+		}
+		parameters = append(parameters, object)
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	content := map[string]interface{}{}
+	if doc.Body != nil {
+		contentType := doc.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		content[contentType] = map[string]interface{}{"schema": builder.schema(reflect.TypeOf(doc.Body))}
+	}
+	if doc.Form != nil {
+		content["application/x-www-form-urlencoded"] = map[string]interface{}{"schema": builder.formSchema(doc.Form)}
+	}
+	if len(content) > 0 {
+		operation["requestBody"] = map[string]interface{}{"required": true, "content": content}
+	}
+
+	status := doc.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	if doc.Response != nil {
+		envelope := map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"data": builder.schema(reflect.TypeOf(doc.Response))},
+			"required":   []string{"data"},
+		}
+		if doc.Meta != nil {
+			envelope["properties"].(map[string]interface{})["meta"] = builder.schema(reflect.TypeOf(doc.Meta))
+		}
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": envelope},
+		}
+	}
+
+	responses := map[string]interface{}{strconv.Itoa(status): success}
+	for _, code := range doc.Errors {
+		responses[strconv.Itoa(code)] = map[string]interface{}{
+			"description": http.StatusText(code),
+			"content": map[string]interface{}{
+				problemMediaType: map[string]interface{}{"schema": problem},
+			},
+		}
+	}
+	operation["responses"] = responses
+	return operation
+}
+
+// operationId unik dari method dan path, misal getPagesId
+func operationID(route apiRoute) string {
+	method := route.method
+	if method == "" {
+		method = "get"
+	}
+	var builder strings.Builder
+	builder.WriteString(method)
+	for _, word := range strings.FieldsFunc(route.path, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		builder.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return builder.String()
+}
+
+// Membuat schema JSON dari tipe Go, struct bernama disimpan di components/schemas
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (builder *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": builder.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": builder.schema(t.Elem())}
+	case t.Kind() == reflect.Struct:
+		name := schemaName(t)
+		if name == "" {
+			return builder.structSchema(t)
+		}
+		if _, ok := builder.components[name]; !ok {
+			// Diisi dulu agar struct yang merujuk dirinya sendiri tidak berulang tanpa akhir
+			builder.components[name] = map[string]interface{}{}
+			builder.components[name] = builder.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default:
+		// interface{} dan tipe lain bisa berisi nilai apa pun
+		return map[string]interface{}{}
+	}
+}
+
+func (builder *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		property := builder.schema(field.Type)
+		if tag, ok := field.Tag.Lookup("validate"); ok {
+			rules := parseValidateTag(tag)
+			applyFieldRules(property, rules)
+			if rules.Required {
+				required = append(required, name)
+			}
+		}
+		properties[name] = property
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Menuliskan aturan tag validate sebagai batasan schema
+func applyFieldRules(schema map[string]interface{}, rules fieldRules) {
+	if _, ok := schema["$ref"]; ok {
+		return
+	}
+
+	minimum, maximum := "minimum", "maximum"
+	switch schema["type"] {
+	case "string":
+		minimum, maximum = "minLength", "maxLength"
+	case "array":
+		minimum, maximum = "minItems", "maxItems"
+	case "object":
+		minimum, maximum = "minProperties", "maxProperties"
+	}
+	if rules.Min != nil {
+		schema[minimum] = *rules.Min
+	}
+	if rules.Max != nil {
+		schema[maximum] = *rules.Max
+	}
+	if rules.Format != "" {
+		schema["format"] = rules.Format
+	}
+	if len(rules.Enum) > 0 {
+		schema["enum"] = rules.Enum
+	}
+}
+
+// Nama schema tanpa path package, misal Record[belajar-golang-web.Page] menjadi RecordPage
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	base, arguments, generic := strings.Cut(name, "[")
+	if !generic {
+		return name
+	}
+
+	var builder strings.Builder
+	builder.WriteString(base)
+	for _, argument := range strings.Split(strings.TrimSuffix(arguments, "]"), ",") {
+		argument = argument[strings.LastIndexAny(argument, "./")+1:]
+		builder.WriteString(strings.Trim(argument, "[]*"))
+	}
+	return builder.String()
+}
+
+// Membaca struct Query: nama dari tag query, batasan dari tag validate
+func (builder *schemaBuilder) queryParameters(query interface{}) []ParameterDoc {
+	if query == nil {
+		return nil
+	}
+	t := reflect.TypeOf(query)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var parameters []ParameterDoc
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		name := field.Tag.Get("query")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		parameter := ParameterDoc{Name: name, In: "query", Description: field.Tag.Get("doc"), Schema: builder.schema(field.Type)}
+		if tag, ok := field.Tag.Lookup("validate"); ok {
+			rules := parseValidateTag(tag)
+			applyFieldRules(parameter.Schema, rules)
+			parameter.Required = rules.Required
+		}
+		parameters = append(parameters, parameter)
+	}
+	return parameters
+}
+
+// Membaca struct Form: nama field dari tag form, batasan dari tag validate
+func (builder *schemaBuilder) formSchema(form interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	t := reflect.TypeOf(form)
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		name := field.Tag.Get("form")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		property := builder.schema(field.Type)
+		if description := field.Tag.Get("doc"); description != "" {
+			property["description"] = description
+		}
+		if tag, ok := field.Tag.Lookup("validate"); ok {
+			rules := parseValidateTag(tag)
+			applyFieldRules(property, rules)
+			if rules.Required {
+				required = append(required, name)
+			}
+		}
+		properties[name] = property
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (router *APIRouter) serveJSON(writer http.ResponseWriter, request *http.Request) {
+	body, err := json.MarshalIndent(router.Document(), "", "  ")
+	if err != nil {
+		WriteProblem(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	writer.Write(body)
+}
+
+func (router *APIRouter) serveYAML(writer http.ResponseWriter, request *http.Request) {
+	body, err := documentYAML(router.Document())
+	if err != nil {
+		WriteProblem(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	io.WriteString(writer, body)
+}
+
+// Halaman explorer diambil dari embed resources, sehingga tetap bisa dipakai tanpa internet
+func serveAPIExplorer(writer http.ResponseWriter, request *http.Request) {
+	name := "resources/openapi.html"
+	if strings.HasSuffix(request.URL.Path, ".js") {
+		name = "resources/openapi.js"
+	}
+	http.ServeFileFS(writer, request, resources, name)
+}
+
+// Mengubah dokumen menjadi YAML
+// Dokumen dilewatkan ke JSON dulu agar struct dan map apa pun menjadi map, slice, dan nilai dasar
+func documentYAML(document interface{}) (string, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	writeYAML(&builder, value, 0)
+	return builder.String(), nil
+}
+
+func writeYAML(builder *strings.Builder, value interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			builder.WriteString(pad + yamlKey(key) + ":")
+			child := value[key]
+			if yamlNested(child) {
+				builder.WriteString("\n")
+				writeYAML(builder, child, indent+2)
+			} else {
+				builder.WriteString(" " + yamlScalar(child) + "\n")
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if !yamlNested(item) {
+				builder.WriteString(pad + "- " + yamlScalar(item) + "\n")
+				continue
+			}
+			// Baris pertama item ditulis setelah "- ", baris berikutnya sejajar dengannya
+			var child strings.Builder
+			writeYAML(&child, item, indent+2)
+			builder.WriteString(pad + "- " + strings.TrimPrefix(child.String(), pad+"  "))
+		}
+	default:
+		builder.WriteString(pad + yamlScalar(value) + "\n")
+	}
+}
+
+// Map dan slice yang tidak kosong ditulis di baris berikutnya
+func yamlNested(value interface{}) bool {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return len(value) > 0
+	case []interface{}:
+		return len(value) > 0
+	}
+	return false
+}
+
+func yamlKey(key string) string {
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return strconv.Quote(key)
+		}
+	}
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		// Key angka seperti "200" tetap string
+		return strconv.Quote(key)
+	}
+	return key
+}
+
+func yamlScalar(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		// String JSON dengan tanda kutip ganda juga valid di YAML
+		return strconv.Quote(value)
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+// Contoh parameter query dengan tag query dan validate
+type NumbersQuery struct {
+	Count *int `query:"count" validate:"required,min=0" doc:"Jumlah angka yang dikirim"`
+}
+
+// Contoh body form untuk FormPost dengan tag form dan validate
+type FormPostRequest struct {
+	FirstName string `form:"first_name" validate:"required,max=50" doc:"Nama depan"`
+	LastName  string `form:"last_name" validate:"max=50" doc:"Nama belakang"`
+}
+
+func apiRouter() *APIRouter {
+	router := NewAPIRouter("Belajar Golang Web", "1.0.0")
+	router.Description = "Contoh API yang dibuat dengan net/http"
+
+	router.Handle("POST /users", Handle(CreateUser), RouteDoc{
+		Summary:  "Membuat user",
+		Tags:     []string{"users"},
+		Body:     CreateUserRequest{},
+		Response: CreateUserRequest{},
+		Status:   http.StatusCreated,
+		Errors:   []int{http.StatusBadRequest, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity},
+	})
+	router.Handle("POST /form", Handle(FormPost), RouteDoc{
+		Summary: "Menyapa dari form",
+		Tags:    []string{"forms"},
+		Form:    FormPostRequest{},
+		Errors:  []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	})
+	router.Handle("GET /numbers", Handle(ListNumbers), RouteDoc{
+		Summary:  "Streaming angka dan kuadratnya",
+		Tags:     []string{"numbers"},
+		Query:    NumbersQuery{},
+		Response: []map[string]int{},
+		Errors:   []int{http.StatusUnprocessableEntity},
+	})
+
+	NewResource("pages", NewMemoryRepository(
+		Page{Title: "Profil", Name: "Hilmi", Address: Address{Street: "Jalan Belum Ada"}},
+	)).RegisterAPI(router)
+	NewResource("addresses", NewMemoryRepository[Address]()).RegisterAPI(router)
+
+	return router
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/openapi.json", nil)
+	recorder := httptest.NewRecorder()
+
+	apiRouter().ServeHTTP(recorder, request)
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+
+	var document struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	err := json.Unmarshal(body, &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if document.OpenAPI != "3.1.0" {
+		t.Errorf("unexpected openapi version %q", document.OpenAPI)
+	}
+	if _, ok := document.Paths["/pages/{id}"]["patch"]; !ok {
+		t.Error("missing PATCH /pages/{id}")
+	}
+	for _, name := range []string{"CreateUserRequest", "RecordPage", "Problem", "ListMeta"} {
+		if _, ok := document.Components.Schemas[name]; !ok {
+			t.Errorf("missing schema %s", name)
+		}
+	}
+
+	// Tag validate menjadi batasan schema
+	email := document.Components.Schemas["CreateUserRequest"]["properties"].(map[string]interface{})["email"].(map[string]interface{})
+	if email["format"] != "email" {
+		t.Errorf("expected email format, got %v", email)
+	}
+
+	// Struct form menjadi body application/x-www-form-urlencoded
+	requestBody, _ := document.Paths["/form"]["post"]["requestBody"].(map[string]interface{})
+	content, _ := requestBody["content"].(map[string]interface{})
+	form, _ := content["application/x-www-form-urlencoded"].(map[string]interface{})
+	schema, _ := form["schema"].(map[string]interface{})
+	fmt.Println(schema)
+	properties, _ := schema["properties"].(map[string]interface{})
+	firstName, _ := properties["first_name"].(map[string]interface{})
+	if firstName["maxLength"] != 50.0 || fmt.Sprint(schema["required"]) != "[first_name]" {
+		t.Errorf("unexpected form schema %v", schema)
+	}
+}
+
+func TestOpenAPIYAML(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/openapi.yaml", nil)
+	recorder := httptest.NewRecorder()
+
+	apiRouter().ServeHTTP(recorder, request)
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(recorder.Result().Header.Get("Content-Type"))
+	fmt.Println(string(body))
+
+	if !strings.Contains(string(body), `openapi: "3.1.0"`) || !strings.Contains(string(body), `  "/pages/{id}":`) {
+		t.Error("unexpected YAML document")
+	}
+}
+
+func TestOpenAPIExplorer(t *testing.T) {
+	router := apiRouter()
+
+	for _, target := range []string{"/docs", "/docs/openapi.js"} {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+target, nil)
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		fmt.Println(target, response.StatusCode, response.Header.Get("Content-Type"))
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", target, response.StatusCode)
+		}
+	}
+}
+
+func TestOpenAPIServer(t *testing.T) {
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: apiRouter(),
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat APIRouter, yaitu ServeMux yang mencatat keterangan setiap route (method, parameter path, struct query, struct form, tipe body, tipe response, dan status error) lalu membuat dokumen OpenAPI 3.1 (struct form ditulis sebagai body application/x-www-form-urlencoded) di /openapi.json dan /openapi.yaml. Schema dibuat dengan reflection dari tipe Go: nama field mengikuti tag json, struct bernama disimpan di components, dan tag validate seperti required, min, max, format, dan oneof menjadi batasan schema. Resource generic cukup memanggil RegisterAPI agar kelima route CRUD-nya ikut terdokumentasi, dan halaman explorer di /docs disajikan dari embed resources sehingga tetap bisa dipakai tanpa koneksi internet.
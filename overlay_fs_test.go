@@ -0,0 +1,283 @@
+package belajar_golang_web
+
+import (
+	"errors"            // Untuk mengecek jenis error (fs.ErrNotExist)
+	"fmt"               // Untuk menampilkan output ke console
+	"html/template"     // Untuk memuat template dari overlay filesystem
+	"io"                // Untuk membaca body response
+	"io/fs"             // Abstraksi filesystem
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"os"                // Untuk membaca folder override di disk
+	"path/filepath"     // Untuk membuat file override saat test
+	"sort"              // Untuk mengurutkan isi folder gabungan
+	"strings"           // Untuk manipulasi string
+	"testing"           // Untuk unit testing
+)
+
+// Nama environment variable untuk folder override di disk,
+// misal OVERLAY_DIR=/srv/patch berisi /srv/patch/resources dan /srv/patch/templates
+const overlayDirectoryEnv = "OVERLAY_DIR"
+
+// Filesystem berlapis: Upper (folder disk) dicek lebih dulu,
+// jika file tidak ada di Upper maka diambil dari Lower (embed.FS)
+type OverlayFS struct {
+	Upper fs.FS // Opsional, folder override di disk
+	Lower fs.FS // Filesystem bawaan yang di-embed ke binary
+}
+
+// Membuat OverlayFS, Upper hanya dipakai jika folder disk benar-benar ada
+func NewOverlayFS(directory string, lower fs.FS) *OverlayFS {
+	overlay := &OverlayFS{Lower: lower}
+	if directory == "" {
+		return overlay
+	}
+	info, err := os.Stat(directory)
+	if err == nil && info.IsDir() {
+		overlay.Upper = os.DirFS(directory)
+	}
+	return overlay
+}
+
+// Implementasi fs.FS
+func (overlay *OverlayFS) Open(name string) (fs.File, error) {
+	if overlay.Upper == nil {
+		return overlay.Lower.Open(name)
+	}
+
+	file, err := overlay.Upper.Open(name)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		return overlay.Lower.Open(name)
+	}
+
+	// Folder yang ada di kedua layer dibuka sebagai folder gabungan
+	info, err := file.Stat()
+	if err == nil && info.IsDir() {
+		return &overlayDir{File: file, overlay: overlay, name: name}, nil
+	}
+	return file, nil
+}
+
+// Implementasi fs.ReadDirFS, isi folder digabung dan Upper menang jika nama sama
+func (overlay *OverlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	merged := map[string]fs.DirEntry{}
+	var firstErr error
+
+	layers := []fs.FS{overlay.Lower}
+	if overlay.Upper != nil {
+		layers = append(layers, overlay.Upper)
+	}
+
+	found := false
+	for _, layer := range layers {
+		entries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if firstErr == nil || errors.Is(firstErr, fs.ErrNotExist) {
+				firstErr = err
+			}
+			continue
+		}
+		found = true
+		for _, entry := range entries {
+			merged[entry.Name()] = entry
+		}
+	}
+	if !found {
+		return nil, firstErr
+	}
+
+	entries := make([]fs.DirEntry, 0, len(merged))
+	for _, entry := range merged {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// Folder hasil Open yang isinya gabungan dari kedua layer
+type overlayDir struct {
+	fs.File
+	overlay *OverlayFS
+	name    string
+	entries []fs.DirEntry
+	offset  int
+	loaded  bool
+}
+
+// Implementasi fs.ReadDirFile, dipakai oleh http.FileServer untuk listing folder
+func (dir *overlayDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	if !dir.loaded {
+		entries, err := dir.overlay.ReadDir(dir.name)
+		if err != nil {
+			return nil, err
+		}
+		dir.entries = entries
+		dir.loaded = true
+	}
+
+	remaining := dir.entries[dir.offset:]
+	if count <= 0 {
+		dir.offset = len(dir.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(remaining) {
+		count = len(remaining)
+	}
+	dir.offset += count
+	return remaining[:count], nil
+}
+
+// Membuat overlay untuk sub folder embed, misal "resources" atau "templates"
+// Folder override di disk diambil dari $OVERLAY_DIR/<sub>
+func overlaySub(embedded fs.FS, sub string) *OverlayFS {
+	lower, err := fs.Sub(embedded, sub)
+	if err != nil {
+		panic(err)
+	}
+
+	directory := os.Getenv(overlayDirectoryEnv)
+	if directory != "" {
+		directory = filepath.Join(directory, sub)
+	}
+	return NewOverlayFS(directory, lower)
+}
+
+// Overlay untuk file statis (resources) dan template (templates)
+var (
+	overlayResources = overlaySub(resources, "resources")
+	overlayTemplates = overlaySub(templates, "templates")
+)
+
+// Static file server yang memakai overlay, ETag dihitung ulang jika file di disk berubah
+func OverlayFileServer() http.Handler {
+	static, err := NewStaticHandler(overlayResources, staticCachePolicies...)
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/static", static)
+}
+
+// Memuat seluruh template dari overlay menjadi satu *template.Template
+func ParseOverlayTemplates(fileSystem fs.FS) (*template.Template, error) {
+	return template.New("").Funcs(StandardFuncs()).ParseFS(fileSystem, "*.gohtml")
+}
+
+func TestOverlayFS(t *testing.T) {
+	// Membuat folder override berisi index.css dan simple.gohtml versi baru
+	directory := t.TempDir()
+	files := map[string]string{
+		filepath.Join("resources", "index.css"):     ".contoh { color: red; }",
+		filepath.Join("resources", "patch.js"):      "console.log('patch')",
+		filepath.Join("templates", "simple.gohtml"): "<h1>Patched {{.}}</h1>",
+	}
+	for name, content := range files {
+		err := os.MkdirAll(filepath.Join(directory, filepath.Dir(name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(directory, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv(overlayDirectoryEnv, directory)
+	resourcesFS := overlaySub(resources, "resources")
+	templatesFS := overlaySub(templates, "templates")
+
+	// File yang ditimpa diambil dari disk, file lain tetap dari embed
+	embedded, err := fs.ReadFile(resources, "resources/index.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"index.css": ".contoh { color: red; }",
+		"index.js":  string(embedded),
+		"patch.js":  "console.log('patch')",
+	}
+	for name, want := range expected {
+		content, err := fs.ReadFile(resourcesFS, name)
+		fmt.Println(name, string(content), err)
+		if err != nil || string(content) != want {
+			t.Errorf("%s: expected %q, got %q (%v)", name, want, content, err)
+		}
+	}
+
+	// Isi folder merupakan gabungan kedua layer
+	entries, err := fs.ReadDir(resourcesFS, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, entry := range entries {
+		fmt.Print(entry.Name(), " ")
+		names[entry.Name()] = true
+	}
+	fmt.Println()
+	for _, name := range []string{"index.css", "index.js", "patch.js"} {
+		if !names[name] {
+			t.Errorf("%s missing from merged ReadDir", name)
+		}
+	}
+
+	// Template juga dimuat dari overlay
+	tmpl, err := ParseOverlayTemplates(templatesFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := new(strings.Builder)
+	err = tmpl.ExecuteTemplate(builder, "simple.gohtml", "Hello Overlay")
+	fmt.Println(builder.String())
+	if err != nil || builder.String() != "<h1>Patched Hello Overlay</h1>" {
+		t.Errorf("expected patched template, got %q (%v)", builder.String(), err)
+	}
+}
+
+func TestOverlayServeFile(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080?name=Hilmi", nil)
+	recorder := httptest.NewRecorder()
+
+	// ServeFileEmbed membaca ok.html lewat overlayResources
+	ServeFileEmbed(recorder, request)
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+	if !strings.Contains(string(body), "<h1>Ok</h1>") {
+		t.Errorf("expected ok.html, got %q", body)
+	}
+}
+
+func TestOverlayServer(t *testing.T) {
+	tmpl, err := ParseOverlayTemplates(overlayTemplates)
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", OverlayFileServer())
+	mux.HandleFunc("/serve-file", ServeFileEmbed)
+	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		tmpl.ExecuteTemplate(writer, "simple.gohtml", "Hello Overlay")
+	})
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: mux,
+	}
+
+	err = server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat OverlayFS, yaitu filesystem berlapis yang mengecek folder disk (dari environment variable OVERLAY_DIR) terlebih dahulu lalu jatuh ke embed.FS jika file tidak ditemukan, dengan isi folder yang digabung dari kedua layer. Overlay yang sama dipakai untuk static file server, handler bergaya ServeFile, dan pemuatan template, sehingga tim operasional dapat menambal template atau file CSS di production tanpa perlu build ulang binary.
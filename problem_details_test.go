@@ -0,0 +1,313 @@
+package belajar_golang_web
+
+import (
+	"context"           // Untuk error timeout
+	"encoding/json"     // Untuk menulis application/problem+json
+	"errors"            // Untuk errors.Is dan errors.As
+	"fmt"               // Untuk logging dan output ke console
+	"io"                // Untuk membaca body response
+	"io/fs"             // Untuk error file tidak ditemukan
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"os"                // Untuk membuka file di test
+	"strings"           // Untuk manipulasi string
+	"testing"           // Untuk unit testing
+)
+
+// Media type untuk Problem Details (RFC 9457)
+const problemMediaType = "application/problem+json"
+
+// Error validasi untuk satu field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Kumpulan error validasi, dikembalikan handler sebagai satu error
+type ValidationErrors []FieldError
+
+func (validation ValidationErrors) Error() string {
+	messages := make([]string, len(validation))
+	for index, fieldError := range validation {
+		messages[index] = fieldError.Field + ": " + fieldError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Menambahkan error untuk satu field
+func (validation *ValidationErrors) Add(field string, message string) {
+	*validation = append(*validation, FieldError{Field: field, Message: message})
+}
+
+// Mengembalikan nil jika tidak ada error, agar bisa langsung dipakai sebagai return value
+func (validation ValidationErrors) Err() error {
+	if len(validation) == 0 {
+		return nil
+	}
+	return validation
+}
+
+// Error aplikasi dalam bentuk Problem Details (RFC 9457)
+type Problem struct {
+	Type     string       `json:"type"`             // URI jenis error, "about:blank" untuk error HTTP biasa
+	Title    string       `json:"title"`            // Ringkasan jenis error, sama untuk setiap kejadian
+	Status   int          `json:"status"`           // Status HTTP
+	Detail   string       `json:"detail,omitempty"` // Penjelasan khusus untuk kejadian ini
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"` // Error per field untuk validasi
+
+	Err error `json:"-"` // Penyebab asli, hanya untuk log
+}
+
+// Membuat Problem dengan type about:blank dan title dari status HTTP
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (problem *Problem) Error() string {
+	if problem.Detail != "" {
+		return fmt.Sprintf("%d %s: %s", problem.Status, problem.Title, problem.Detail)
+	}
+	return fmt.Sprintf("%d %s", problem.Status, problem.Title)
+}
+
+func (problem *Problem) Unwrap() error {
+	return problem.Err
+}
+
+// Aturan pemetaan error Go menjadi Problem
+type errorMapping func(err error) (*Problem, bool)
+
+// Registry yang memetakan error Go ke status, type URI, dan title
+type ErrorRegistry struct {
+	mappings []errorMapping
+}
+
+// Mendaftarkan error sentinel (dicek dengan errors.Is)
+func (registry *ErrorRegistry) Register(target error, status int, typeURI string, title string) {
+	registry.RegisterFunc(func(err error) (*Problem, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+		return &Problem{Type: typeURI, Title: title, Status: status, Err: err}, true
+	})
+}
+
+// Mendaftarkan aturan sendiri, misal untuk tipe error yang dicek dengan errors.As
+func (registry *ErrorRegistry) RegisterFunc(mapping func(err error) (*Problem, bool)) {
+	registry.mappings = append(registry.mappings, mapping)
+}
+
+// Mengubah error menjadi Problem, error yang tidak dikenal menjadi 500 tanpa detail
+func (registry *ErrorRegistry) Problem(err error) *Problem {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		// Disalin agar Problem yang dipakai bersama (variabel global) tidak ikut berubah
+		copied := *problem
+		return &copied
+	}
+
+	// Aturan yang didaftarkan belakangan diperiksa lebih dulu agar bisa menimpa aturan bawaan
+	for index := len(registry.mappings) - 1; index >= 0; index-- {
+		if problem, ok := registry.mappings[index](err); ok {
+			return problem
+		}
+	}
+
+	problem = NewProblem(http.StatusInternalServerError, "")
+	problem.Err = err
+	return problem
+}
+
+// Registry bawaan dengan pemetaan error yang umum
+var defaultErrorRegistry = newDefaultErrorRegistry()
+
+func newDefaultErrorRegistry() *ErrorRegistry {
+	registry := &ErrorRegistry{}
+	registry.Register(fs.ErrNotExist, http.StatusNotFound, "/problems/not-found", "Resource Not Found")
+	registry.Register(fs.ErrPermission, http.StatusForbidden, "/problems/forbidden", "Access Denied")
+	registry.Register(context.DeadlineExceeded, http.StatusGatewayTimeout, "/problems/timeout", "Gateway Timeout")
+	registry.Register(ErrRecordNotFound, http.StatusNotFound, "/problems/not-found", "Resource Not Found")
+	registry.Register(ErrVersionConflict, http.StatusPreconditionFailed, "/problems/version-conflict", "Version Conflict")
+
+	registry.RegisterFunc(func(err error) (*Problem, bool) {
+		var maxBytesError *http.MaxBytesError
+		if !errors.As(err, &maxBytesError) {
+			return nil, false
+		}
+		return &Problem{
+			Type:   "/problems/payload-too-large",
+			Title:  "Payload Too Large",
+			Status: http.StatusRequestEntityTooLarge,
+			Detail: fmt.Sprintf("body request melebihi batas %d byte", maxBytesError.Limit),
+			Err:    err,
+		}, true
+	})
+
+	registry.RegisterFunc(func(err error) (*Problem, bool) {
+		var validation ValidationErrors
+		if !errors.As(err, &validation) {
+			return nil, false
+		}
+		return &Problem{
+			Type:   "/problems/validation",
+			Title:  "Validation Failed",
+			Status: http.StatusUnprocessableEntity,
+			Detail: "beberapa field tidak valid",
+			Errors: validation,
+			Err:    err,
+		}, true
+	})
+	return registry
+}
+
+// Mengecek apakah client lebih memilih HTML (browser) daripada JSON (API client)
+func prefersHTML(request *http.Request) bool {
+	header := request.Header.Get("Accept")
+	if header == "" {
+		return false
+	}
+	ranges := parseAccept(header)
+	html := acceptQuality(ranges, "text/html")
+	json := max(acceptQuality(ranges, problemMediaType), acceptQuality(ranges, "application/json"))
+	return html > json
+}
+
+// Menulis error sebagai application/problem+json untuk API client,
+// atau sebagai halaman error HTML (ErrorPage) untuk browser
+func WriteProblem(writer http.ResponseWriter, request *http.Request, err error) {
+	problem := defaultErrorRegistry.Problem(err)
+	if problem.Instance == "" {
+		problem.Instance = request.URL.Path
+	}
+
+	if problem.Status >= 500 {
+		// Penyebab error server hanya dicatat di log, tidak dikirim ke client
+		fmt.Printf("Server error : method=%s path=%s remote=%s user_agent=%q status=%d error=%v\n",
+			request.Method, request.URL.Path, request.RemoteAddr, request.UserAgent(), problem.Status, err)
+	}
+
+	addVary(writer.Header(), "Accept")
+	if prefersHTML(request) {
+		message := problem.Detail
+		if len(problem.Errors) > 0 {
+			message = ValidationErrors(problem.Errors).Error()
+		}
+		ErrorPage(writer, request, problem.Status, message)
+		return
+	}
+
+	body, err := json.Marshal(problem)
+	if err != nil {
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", problemMediaType)
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	writer.WriteHeader(problem.Status)
+	writer.Write(body)
+}
+
+// Contoh handler yang mengembalikan berbagai jenis error
+func ProblemExample(writer http.ResponseWriter, request *http.Request) {
+	switch request.URL.Query().Get("case") {
+	case "validation":
+		var validation ValidationErrors
+		validation.Add("name", "wajib diisi")
+		validation.Add("email", "format email tidak valid")
+		WriteProblem(writer, request, validation)
+	case "file":
+		_, err := os.Open("./resources/tidak-ada.png")
+		WriteProblem(writer, request, err)
+	case "bad-request":
+		WriteProblem(writer, request, NewProblem(http.StatusBadRequest, "name is empty"))
+	default:
+		WriteProblem(writer, request, errors.New("koneksi database terputus"))
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	tests := []struct {
+		target string
+		accept string
+		status int
+	}{
+		{"/?case=validation", "application/json", http.StatusUnprocessableEntity},
+		{"/?case=file", "", http.StatusNotFound},
+		{"/?case=bad-request", "application/problem+json", http.StatusBadRequest},
+		{"/?case=database", "", http.StatusInternalServerError},
+		{"/?case=validation", "text/html,application/xhtml+xml,*/*;q=0.8", http.StatusUnprocessableEntity},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+test.target, nil)
+		if test.accept != "" {
+			request.Header.Set("Accept", test.accept)
+		}
+		recorder := httptest.NewRecorder()
+
+		ProblemExample(recorder, request)
+
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(response.StatusCode, response.Header.Get("Content-Type"))
+		fmt.Println(string(body))
+
+		if response.StatusCode != test.status {
+			t.Errorf("%s: expected %d, got %d", test.target, test.status, response.StatusCode)
+		}
+	}
+}
+
+func TestErrorRegistry(t *testing.T) {
+	registry := newDefaultErrorRegistry()
+
+	// Error khusus aplikasi bisa didaftarkan sendiri
+	errOutOfStock := errors.New("stok habis")
+	registry.Register(errOutOfStock, http.StatusConflict, "/problems/out-of-stock", "Out Of Stock")
+
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("membuka file: %w", fs.ErrNotExist), http.StatusNotFound},
+		{&http.MaxBytesError{Limit: 1024}, http.StatusRequestEntityTooLarge},
+		{ValidationErrors{{Field: "name", Message: "wajib diisi"}}, http.StatusUnprocessableEntity},
+		{fmt.Errorf("checkout: %w", errOutOfStock), http.StatusConflict},
+		{fmt.Errorf("query database: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{NewProblem(http.StatusTeapot, ""), http.StatusTeapot},
+		{errors.New("tidak dikenal"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		problem := registry.Problem(test.err)
+		fmt.Println(test.err, "=>", problem.Status, problem.Type, problem.Title)
+		if problem.Status != test.status {
+			t.Errorf("%v: expected %d, got %d", test.err, test.status, problem.Status)
+		}
+		// Judul mengikuti status, misal 504 "Gateway Timeout" untuk context.DeadlineExceeded
+		if problem.Status == http.StatusGatewayTimeout && problem.Title != http.StatusText(http.StatusGatewayTimeout) {
+			t.Errorf("%v: unexpected title %q", test.err, problem.Title)
+		}
+	}
+}
+
+func TestProblemDetailsServer(t *testing.T) {
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: http.HandlerFunc(ProblemExample),
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat bentuk error yang konsisten mengikuti RFC 9457 Problem Details: tipe Problem membawa status, type URI, title, detail, dan error per field. ErrorRegistry memetakan error Go seperti fs.ErrNotExist, http.MaxBytesError, dan ValidationErrors ke status HTTP yang sesuai, sementara error yang tidak dikenal menjadi 500 tanpa membocorkan detail. WriteProblem mengirim application/problem+json untuk API client dan halaman error HTML lewat ErrorPage untuk browser, sehingga handler cukup mengembalikan error tanpa perlu panic.
@@ -0,0 +1,197 @@
+package belajar_golang_web
+
+import (
+	"bytes"             // Buffer tempat template dirender sebelum dikirim
+	"fmt"               // Untuk logging dan output ke console
+	"html/template"     // Package template HTML bawaan Go
+	"io"                // Untuk interface writer
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"regexp"            // Untuk mengambil action yang gagal dari pesan error
+	"strconv"           // Untuk menulis Content-Length
+	"strings"           // Untuk memeriksa isi response
+	"sync"              // Untuk pool buffer
+	"testing"           // Untuk unit testing
+)
+
+// Apa saja yang bisa mengeksekusi template berdasarkan nama,
+// misal *template.Template atau *TemplateEngine (myTemplates)
+type TemplateExecutor interface {
+	ExecuteTemplate(writer io.Writer, name string, data interface{}) error
+}
+
+// Pool buffer agar tidak membuat buffer baru di setiap request
+var renderBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// Buffer yang terlalu besar tidak dikembalikan ke pool agar memory tidak membengkak
+const maxPooledBufferSize = 1 << 20
+
+func getRenderBuffer() *bytes.Buffer {
+	buffer := renderBufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	return buffer
+}
+
+func putRenderBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() <= maxPooledBufferSize {
+		renderBufferPool.Put(buffer)
+	}
+}
+
+// Pola error eksekusi template, misal: executing "simple.gohtml" at <.Foo>: ...
+var templateActionPattern = regexp.MustCompile(`at <(.+?)>:`)
+
+// Mengambil action template yang gagal dari pesan error
+func failingTemplateAction(err error) string {
+	match := templateActionPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// Menulis halaman error menggunakan template error.gohtml
+// Dipakai sebagai satu-satunya jalur untuk menampilkan halaman error HTML
+func ErrorPage(writer http.ResponseWriter, request *http.Request, status int, message string) {
+	buffer := getRenderBuffer()
+	defer putRenderBuffer(buffer)
+
+	err := myTemplates.ExecuteTemplate(buffer, "error.gohtml", map[string]interface{}{
+		"Status":     status,
+		"StatusText": http.StatusText(status),
+		"Message":    message,
+	})
+	if err != nil {
+		// Template error pun gagal, kirim teks biasa
+		http.Error(writer, http.StatusText(status), status)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.Header().Set("Content-Length", strconv.Itoa(buffer.Len()))
+	writer.WriteHeader(status)
+	buffer.WriteTo(writer)
+}
+
+// Mencatat error render beserta nama template dan action yang gagal
+func logRenderError(request *http.Request, name string, err error) {
+	fmt.Printf("Render error : method=%s path=%s template=%s action=%s error=%v\n",
+		request.Method, request.URL.Path, name, failingTemplateAction(err), err)
+}
+
+// Merender template ke buffer terlebih dahulu, baru dikirim ke client jika berhasil
+// Jika gagal, client mendapat 500 lewat ErrorPage, bukan HTML setengah jadi dengan status 200
+func Render(writer http.ResponseWriter, request *http.Request, executor TemplateExecutor, name string, data interface{}) {
+	buffer := getRenderBuffer()
+	defer putRenderBuffer(buffer)
+
+	err := executor.ExecuteTemplate(buffer, name, data)
+	if err != nil {
+		logRenderError(request, name, err)
+		// TemplateEngine mode development menampilkan file dan baris error di browser
+		if engine, ok := executor.(*TemplateEngine); ok && engine.Development {
+			engine.writeErrorPage(writer, err)
+			return
+		}
+		ErrorPage(writer, request, http.StatusInternalServerError, "")
+		return
+	}
+
+	if writer.Header().Get("Content-Type") == "" {
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	writer.Header().Set("Content-Length", strconv.Itoa(buffer.Len()))
+	buffer.WriteTo(writer)
+}
+
+// Mode streaming untuk halaman yang sangat besar, template langsung ditulis ke response
+// Status tidak bisa diubah jika error terjadi di tengah jalan, sehingga error hanya dicatat
+func RenderStream(writer http.ResponseWriter, request *http.Request, executor TemplateExecutor, name string, data interface{}) {
+	if writer.Header().Get("Content-Type") == "" {
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+
+	err := executor.ExecuteTemplate(writer, name, data)
+	if err != nil {
+		logRenderError(request, name, err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+	recorder := httptest.NewRecorder()
+
+	Render(recorder, request, myTemplates, "simple.gohtml", "Hello Render")
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+
+	fmt.Println(response.StatusCode, response.Header.Get("Content-Type"), response.Header.Get("Content-Length"))
+	fmt.Println(string(body))
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("unexpected response %d %q", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	if response.Header.Get("Content-Length") != strconv.Itoa(len(body)) || !strings.Contains(string(body), "<h1>Hello Render</h1>") {
+		t.Errorf("unexpected body (Content-Length %s): %q", response.Header.Get("Content-Length"), body)
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	// Template yang gagal di tengah halaman karena memanggil method yang tidak ada
+	broken := template.Must(template.New("broken.gohtml").Parse(
+		`<html><body><h1>{{.Name}}</h1>{{.SayGoodbye}}</body></html>`,
+	))
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+	recorder := httptest.NewRecorder()
+
+	Render(recorder, request, broken, "broken.gohtml", MyPage{Name: "Hilmi"})
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+
+	// Tidak ada potongan "<h1>Hilmi</h1>" di response, hanya halaman error 500
+	fmt.Println(response.StatusCode)
+	fmt.Println(string(body))
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", response.StatusCode)
+	}
+	if strings.Contains(string(body), "<h1>Hilmi</h1>") {
+		t.Error("partial template output leaked into the error response")
+	}
+	// Content-Length milik halaman error, bukan milik output template yang gagal
+	if response.Header.Get("Content-Length") != strconv.Itoa(len(body)) {
+		t.Errorf("Content-Length %q does not match body length %d", response.Header.Get("Content-Length"), len(body))
+	}
+}
+
+func TestRenderStream(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+	recorder := httptest.NewRecorder()
+
+	RenderStream(recorder, request, myTemplates, "range.gohtml", map[string]interface{}{
+		"Title":   "Render Stream",
+		"Hobbies": []string{"Game", "Watch", "Code"},
+	})
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	fmt.Println(string(body))
+
+	// Streaming langsung ke response sehingga Content-Length tidak diketahui di awal
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Length") != "" {
+		t.Errorf("unexpected response %d Content-Length %q", response.StatusCode, response.Header.Get("Content-Length"))
+	}
+	for _, hobby := range []string{"<h1>0 - Game</h1>", "<h1>1 - Watch</h1>", "<h1>2 - Code</h1>"} {
+		if !strings.Contains(string(body), hobby) {
+			t.Errorf("expected %q in body", hobby)
+		}
+	}
+}
+
+// Kesimpulan:
+// Kode ini menambahkan helper Render yang mengeksekusi template ke buffer dari sync.Pool terlebih dahulu, lalu mengirimkannya dengan Content-Type dan Content-Length yang benar. Jika template gagal di tengah eksekusi, client tidak lagi menerima HTML setengah jadi dengan status 200, melainkan halaman error 500 dari ErrorPage, sementara nama template dan action yang gagal dicatat ke log. Mode RenderStream tetap tersedia untuk halaman yang sangat besar.
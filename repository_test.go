@@ -0,0 +1,272 @@
+package belajar_golang_web
+
+import (
+	"cmp"           // Untuk membandingkan nilai saat sorting
+	"encoding/json" // Untuk membaca field data secara generic
+	"errors"        // Untuk error sentinel
+	"fmt"           // Untuk format nilai dan output ke console
+	"slices"        // Untuk sorting dan pagination
+	"strconv"       // Untuk membuat ID
+	"strings"       // Untuk memecah path field
+	"sync"          // Untuk mengamankan akses data dari banyak goroutine
+	"testing"       // Untuk unit testing
+)
+
+// Error yang dikembalikan Repository, dipetakan ke status HTTP oleh ErrorRegistry
+var (
+	ErrRecordNotFound  = errors.New("data tidak ditemukan")
+	ErrVersionConflict = errors.New("data sudah diubah oleh request lain")
+)
+
+// Data yang disimpan beserta ID dan versinya
+// Version bertambah setiap kali data diubah dan dipakai sebagai ETag
+type Record[T any] struct {
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+	Value   T      `json:"value"`
+}
+
+// Parameter list: pagination, sorting, dan filter
+type ListQuery struct {
+	Offset     int
+	Limit      int
+	Sort       string            // Nama field, misal "Title" atau "Address.Street"
+	Descending bool              // Urutan terbalik, dari parameter sort=-Title
+	Filters    map[string]string // Field yang nilainya harus sama persis
+}
+
+// Penyimpanan data yang dipakai Resource
+// Version 0 pada Update dan Delete berarti tanpa pengecekan versi
+type Repository[T any] interface {
+	List(query ListQuery) (records []Record[T], total int, err error)
+	Get(id string) (Record[T], error)
+	Create(value T) (Record[T], error)
+	Update(id string, version int, value T) (Record[T], error)
+	Delete(id string, version int) error
+}
+
+// Implementasi Repository di memory, cocok untuk contoh dan testing
+type MemoryRepository[T any] struct {
+	mutex   sync.RWMutex
+	records map[string]Record[T]
+	order   []string // Urutan data dibuat, dipakai jika tidak ada sort
+	nextID  int
+}
+
+func NewMemoryRepository[T any](values ...T) *MemoryRepository[T] {
+	repository := &MemoryRepository[T]{records: make(map[string]Record[T])}
+	for _, value := range values {
+		repository.Create(value)
+	}
+	return repository
+}
+
+func (repository *MemoryRepository[T]) List(query ListQuery) ([]Record[T], int, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	type row struct {
+		record Record[T]
+		fields map[string]interface{}
+	}
+
+	rows := make([]row, 0, len(repository.order))
+	for _, id := range repository.order {
+		record := repository.records[id]
+		fields, err := recordFields(record)
+		if err != nil {
+			return nil, 0, err
+		}
+		if matchFilters(fields, query.Filters) {
+			rows = append(rows, row{record: record, fields: fields})
+		}
+	}
+
+	if query.Sort != "" {
+		slices.SortStableFunc(rows, func(a, b row) int {
+			result := compareFieldValues(a.fields[query.Sort], b.fields[query.Sort])
+			if query.Descending {
+				return -result
+			}
+			return result
+		})
+	}
+
+	total := len(rows)
+	start := min(max(query.Offset, 0), total)
+	end := total
+	if query.Limit > 0 {
+		end = min(start+query.Limit, total)
+	}
+
+	records := make([]Record[T], 0, end-start)
+	for _, row := range rows[start:end] {
+		records = append(records, row.record)
+	}
+	return records, total, nil
+}
+
+func (repository *MemoryRepository[T]) Get(id string) (Record[T], error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	record, ok := repository.records[id]
+	if !ok {
+		return Record[T]{}, ErrRecordNotFound
+	}
+	return record, nil
+}
+
+func (repository *MemoryRepository[T]) Create(value T) (Record[T], error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.nextID++
+	record := Record[T]{ID: strconv.Itoa(repository.nextID), Version: 1, Value: value}
+	repository.records[record.ID] = record
+	repository.order = append(repository.order, record.ID)
+	return record, nil
+}
+
+func (repository *MemoryRepository[T]) Update(id string, version int, value T) (Record[T], error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	record, ok := repository.records[id]
+	if !ok {
+		return Record[T]{}, ErrRecordNotFound
+	}
+	// Cek dan ubah di bawah lock yang sama agar dua request tidak saling menimpa
+	if version != 0 && version != record.Version {
+		return Record[T]{}, ErrVersionConflict
+	}
+
+	record.Version++
+	record.Value = value
+	repository.records[id] = record
+	return record, nil
+}
+
+func (repository *MemoryRepository[T]) Delete(id string, version int) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	record, ok := repository.records[id]
+	if !ok {
+		return ErrRecordNotFound
+	}
+	if version != 0 && version != record.Version {
+		return ErrVersionConflict
+	}
+
+	delete(repository.records, id)
+	repository.order = slices.DeleteFunc(repository.order, func(value string) bool {
+		return value == id
+	})
+	return nil
+}
+
+// Mengubah record menjadi map field berdasarkan nama JSON-nya,
+// struct di dalam struct digabung dengan titik, misal "Address.Street"
+func recordFields[T any](record Record[T]) (map[string]interface{}, error) {
+	data, err := json.Marshal(record.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{"id": record.ID}
+	flattenFields(fields, "", value)
+	return fields, nil
+}
+
+func flattenFields(fields map[string]interface{}, prefix string, value interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		if prefix != "" {
+			fields[prefix] = value
+		}
+		return
+	}
+	for key, child := range object {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		flattenFields(fields, name, child)
+	}
+}
+
+func matchFilters(fields map[string]interface{}, filters map[string]string) bool {
+	for name, expected := range filters {
+		value, ok := fields[name]
+		if !ok || fmt.Sprint(value) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+// Angka dibandingkan sebagai angka, selain itu sebagai teks tanpa membedakan huruf besar
+func compareFieldValues(a, b interface{}) int {
+	numberA, okA := a.(float64)
+	numberB, okB := b.(float64)
+	if okA && okB {
+		return cmp.Compare(numberA, numberB)
+	}
+	if a == nil || b == nil {
+		// Field yang tidak ada diletakkan di akhir
+		return cmp.Compare(boolToInt(a == nil), boolToInt(b == nil))
+	}
+	return cmp.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b)))
+}
+
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func TestMemoryRepository(t *testing.T) {
+	repository := NewMemoryRepository(
+		Page{Title: "Profil", Name: "Hilmi", Address: Address{Street: "Jalan Belum Ada"}},
+		Page{Title: "Artikel", Name: "Budi", Address: Address{Street: "Jalan Merdeka"}},
+		Page{Title: "Kontak", Name: "Hilmi", Address: Address{Street: "Jalan Sudirman"}},
+	)
+
+	records, total, _ := repository.List(ListQuery{Sort: "Title"})
+	fmt.Println(total, records)
+
+	records, total, _ = repository.List(ListQuery{Filters: map[string]string{"Name": "Hilmi"}, Sort: "Address.Street", Descending: true, Limit: 1})
+	fmt.Println(total, records)
+	if total != 2 || len(records) != 1 || records[0].Value.Title != "Kontak" {
+		t.Errorf("unexpected list result: %d %v", total, records)
+	}
+
+	updated, err := repository.Update("1", 1, Page{Title: "Profil Baru", Name: "Hilmi"})
+	fmt.Println(updated, err)
+
+	// Versi 1 sudah tidak berlaku setelah update
+	_, err = repository.Update("1", 1, Page{Title: "Profil Lama", Name: "Hilmi"})
+	fmt.Println(err)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected version conflict, got %v", err)
+	}
+
+	err = repository.Delete("2", 0)
+	fmt.Println(err)
+	_, err = repository.Get("2")
+	fmt.Println(err)
+	if !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat interface Repository generic sebagai penyimpanan data untuk REST resource, beserta implementasi MemoryRepository yang aman dipakai banyak goroutine. Setiap data disimpan sebagai Record dengan ID dan Version; Version bertambah pada setiap perubahan dan dicek di bawah lock yang sama sehingga update yang bersamaan tidak saling menimpa (optimistic concurrency). List mendukung filter, sorting berdasarkan nama field JSON termasuk field bertingkat seperti Address.Street, serta pagination dengan offset dan limit.
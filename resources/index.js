@@ -1,3 +1,147 @@
 function Hello() {
     
-}
\ No newline at end of file
+}
+
+// Progress upload untuk form dengan atribut data-upload-progress (lihat upload.form.gohtml)
+// Form dikirim lewat fetch dengan ?upload_id=, lalu progress dibaca dari server:
+// polling JSON sampai upload terdaftar, kemudian Server-Sent Events jika browser mendukung
+function createUploadID() {
+    if (window.crypto && crypto.randomUUID) {
+        return crypto.randomUUID();
+    }
+    return Date.now().toString(36) + '-' + Math.random().toString(36).slice(2);
+}
+
+function formatBytes(bytes) {
+    var units = ['B', 'KB', 'MB', 'GB'];
+    var index = 0;
+    while (bytes >= 1024 && index < units.length - 1) {
+        bytes /= 1024;
+        index++;
+    }
+    return bytes.toFixed(index === 0 ? 0 : 1) + ' ' + units[index];
+}
+
+// Memanggil onProgress setiap ada perubahan, mengembalikan fungsi untuk berhenti
+function watchUploadProgress(id, onProgress) {
+    var url = '/upload/progress/' + encodeURIComponent(id);
+    var stopped = false;
+    var timer = null;
+    var source = null;
+
+    function update(progress) {
+        onProgress(progress);
+        if (progress.done) {
+            stop();
+        }
+    }
+
+    function listen() {
+        source = new EventSource(url + '/events');
+        source.addEventListener('progress', function (event) {
+            update(JSON.parse(event.data));
+        });
+        source.onerror = function () {
+            // Stream terputus permanen (misal progress sudah kedaluwarsa), kembali ke polling
+            if (source.readyState === EventSource.CLOSED && !stopped) {
+                source = null;
+                timer = setTimeout(poll, 500);
+            }
+        };
+    }
+
+    function poll() {
+        fetch(url, {cache: 'no-store'})
+            .then(function (response) {
+                // 404 berarti request upload belum sampai ke server
+                return response.ok ? response.json() : null;
+            })
+            .then(function (body) {
+                if (stopped) {
+                    return;
+                }
+                if (body) {
+                    update(body.data);
+                    if (!stopped && window.EventSource) {
+                        listen();
+                        return;
+                    }
+                }
+                if (!stopped) {
+                    timer = setTimeout(poll, 500);
+                }
+            })
+            .catch(function () {
+                if (!stopped) {
+                    timer = setTimeout(poll, 1000);
+                }
+            });
+    }
+
+    function stop() {
+        stopped = true;
+        clearTimeout(timer);
+        if (source) {
+            source.close();
+        }
+    }
+
+    poll();
+    return stop;
+}
+
+function initUploadForm(form) {
+    var bar = form.querySelector('progress');
+    var status = form.querySelector('[data-upload-status]');
+
+    function showProgress(progress) {
+        if (progress.total > 0) {
+            bar.max = progress.total;
+            bar.value = progress.received;
+            var percent = Math.floor(progress.received * 100 / progress.total);
+            status.textContent = formatBytes(progress.received) + ' dari ' + formatBytes(progress.total) + ' (' + percent + '%)';
+        } else {
+            bar.removeAttribute('value'); // Ukuran tidak diketahui, progress bar tanpa nilai
+            status.textContent = formatBytes(progress.received) + ' diterima';
+        }
+        if (progress.error) {
+            status.textContent = 'Upload gagal: ' + progress.error;
+        }
+    }
+
+    form.addEventListener('submit', function (event) {
+        event.preventDefault();
+
+        var id = createUploadID();
+        var action = new URL(form.action, window.location.href);
+        action.searchParams.set('upload_id', id);
+
+        var submit = form.querySelector('[type=submit]');
+        submit.disabled = true;
+        bar.hidden = false;
+        bar.value = 0;
+        status.textContent = 'Mengirim...';
+
+        var stop = watchUploadProgress(id, showProgress);
+        fetch(action, {method: 'POST', body: new FormData(form)})
+            .then(function (response) {
+                return response.text();
+            })
+            .then(function (html) {
+                stop();
+                // Menampilkan halaman hasil dari server, sama seperti submit form biasa
+                var page = new DOMParser().parseFromString(html, 'text/html');
+                document.title = page.title;
+                document.body.replaceWith(document.adoptNode(page.body));
+            })
+            .catch(function (error) {
+                stop();
+                submit.disabled = false;
+                status.textContent = 'Upload gagal: ' + error.message;
+            });
+    });
+}
+
+document.addEventListener('DOMContentLoaded', function () {
+    document.querySelectorAll('form[data-upload-progress]').forEach(initUploadForm);
+});
\ No newline at end of file
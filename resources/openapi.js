@@ -0,0 +1,146 @@
+// API explorer sederhana tanpa library dari internet
+// Semua teks ditulis dengan textContent agar isi dokumen tidak dijalankan sebagai HTML
+
+function element(tag, text) {
+    const node = document.createElement(tag);
+    if (text !== undefined) {
+        node.textContent = text;
+    }
+    return node;
+}
+
+// Mengikuti $ref ke components/schemas
+function resolve(spec, schema) {
+    while (schema && schema.$ref) {
+        schema = spec.components.schemas[schema.$ref.split("/").pop()];
+    }
+    return schema || {};
+}
+
+// Contoh nilai dari schema untuk isi awal body request
+function sample(spec, schema, depth) {
+    schema = resolve(spec, schema);
+    if (depth > 5) {
+        return null;
+    }
+    if (schema.enum) {
+        return schema.enum[0];
+    }
+    switch (schema.type) {
+        case "object": {
+            const value = {};
+            for (const [name, property] of Object.entries(schema.properties || {})) {
+                value[name] = sample(spec, property, depth + 1);
+            }
+            return value;
+        }
+        case "array":
+            return [];
+        case "integer":
+        case "number":
+            return schema.minimum || 0;
+        case "boolean":
+            return false;
+        case "string":
+            return schema.format === "email" ? "nama@example.com" : "";
+    }
+    return null;
+}
+
+function renderOperation(spec, path, method, operation) {
+    const section = element("details");
+    section.appendChild(element("summary", method.toUpperCase() + " " + path + " - " + (operation.summary || "")));
+
+    const form = element("form");
+    const inputs = {};
+    for (const parameter of operation.parameters || []) {
+        const label = element("label", parameter.name + " (" + parameter.in + ")" + (parameter.required ? " *" : "") + " ");
+        const input = element("input");
+        input.name = parameter.name;
+        input.placeholder = parameter.description || "";
+        inputs[parameter.name] = { parameter, input };
+        label.appendChild(input);
+        form.appendChild(label);
+        form.appendChild(element("br"));
+    }
+
+    let body = null;
+    let contentType = null;
+    if (operation.requestBody) {
+        [contentType] = Object.keys(operation.requestBody.content);
+        body = element("textarea");
+        body.rows = 8;
+        body.cols = 60;
+        body.value = JSON.stringify(sample(spec, operation.requestBody.content[contentType].schema, 0), null, 2);
+        form.appendChild(body);
+        form.appendChild(element("br"));
+    }
+
+    const ifMatch = element("input");
+    ifMatch.placeholder = "If-Match (opsional)";
+    if (["put", "patch", "delete"].includes(method)) {
+        form.appendChild(ifMatch);
+        form.appendChild(element("br"));
+    }
+
+    const button = element("button", "Kirim");
+    button.type = "submit";
+    form.appendChild(button);
+
+    const result = element("pre");
+    form.addEventListener("submit", async (event) => {
+        event.preventDefault();
+
+        let url = path;
+        const query = new URLSearchParams();
+        for (const { parameter, input } of Object.values(inputs)) {
+            if (parameter.in === "path") {
+                url = url.replace("{" + parameter.name + "}", encodeURIComponent(input.value));
+            } else if (parameter.in === "query" && input.value !== "") {
+                query.set(parameter.name, input.value);
+            }
+        }
+        if (query.toString() !== "") {
+            url += "?" + query.toString();
+        }
+
+        const headers = { Accept: "application/json" };
+        if (body) {
+            headers["Content-Type"] = contentType;
+        }
+        if (ifMatch.value !== "") {
+            headers["If-Match"] = ifMatch.value;
+        }
+
+        try {
+            const response = await fetch(url, { method: method.toUpperCase(), headers, body: body ? body.value : undefined });
+            const text = await response.text();
+            const etag = response.headers.get("ETag");
+            result.textContent = response.status + " " + response.statusText + (etag ? "\nETag: " + etag : "") + "\n\n" + text;
+        } catch (error) {
+            result.textContent = String(error);
+        }
+    });
+
+    section.appendChild(form);
+    section.appendChild(result);
+    return section;
+}
+
+async function loadExplorer() {
+    const response = await fetch("/openapi.json");
+    const spec = await response.json();
+
+    document.title = spec.info.title;
+    document.getElementById("title").textContent = spec.info.title + " " + spec.info.version;
+    document.getElementById("description").textContent = spec.info.description || "";
+
+    const container = document.getElementById("operations");
+    for (const path of Object.keys(spec.paths).sort()) {
+        for (const [method, operation] of Object.entries(spec.paths[path])) {
+            container.appendChild(renderOperation(spec, path, method, operation));
+        }
+    }
+}
+
+document.addEventListener("DOMContentLoaded", loadExplorer);
@@ -0,0 +1,524 @@
+package belajar_golang_web
+
+import (
+	"fmt"               // Untuk menampilkan output ke console
+	"html"              // Untuk escape dan unescape entity HTML
+	"html/template"     // Untuk tipe template.HTML
+	"io"                // Untuk membaca body response
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"net/url"           // Untuk memeriksa skema URL
+	"strings"           // Untuk manipulasi string
+	"testing"           // Untuk unit testing
+	"unicode"           // Untuk mengecek karakter nama tag dan atribut
+)
+
+// Aturan sanitizer: hanya tag, atribut, dan skema URL yang ada di allowlist yang dipertahankan
+type SanitizerPolicy struct {
+	Tags             map[string][]string // Tag yang diizinkan beserta atributnya
+	GlobalAttributes []string            // Atribut yang diizinkan di semua tag, misal "title"
+	URLAttributes    []string            // Atribut yang berisi URL dan wajib dicek skemanya
+	URLSchemes       []string            // Skema URL yang diizinkan, misal "https"
+	NoFollow         bool                // Menambahkan rel="nofollow" pada setiap link
+	AllowStyle       bool                // false = atribut style selalu dibuang
+}
+
+// Tag yang isinya ikut dibuang, bukan hanya tagnya
+var sanitizerDropContentTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "textarea": true, "title": true,
+	"xmp": true, "noembed": true, "noframes": true, "plaintext": true, "frameset": true,
+	"svg": true, "math": true, "select": true, "applet": true,
+}
+
+// Tag yang tidak memiliki penutup
+var sanitizerVoidTags = map[string]bool{
+	"br": true, "hr": true, "img": true, "wbr": true, "col": true,
+}
+
+// Policy paling ketat: semua tag dibuang, hanya teks yang tersisa
+func StrictPolicy() *SanitizerPolicy {
+	return &SanitizerPolicy{Tags: map[string][]string{}}
+}
+
+// Policy untuk konten buatan user (komentar, artikel, deskripsi)
+func UGCPolicy() *SanitizerPolicy {
+	return &SanitizerPolicy{
+		Tags: map[string][]string{
+			"p": nil, "br": nil, "hr": nil, "div": nil, "span": nil,
+			"b": nil, "strong": nil, "i": nil, "em": nil, "u": nil, "s": nil, "del": nil, "ins": nil, "mark": nil,
+			"sub": nil, "sup": nil, "small": nil,
+			"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+			"ul": nil, "ol": {"start"}, "li": nil, "dl": nil, "dt": nil, "dd": nil,
+			"blockquote": {"cite"}, "q": {"cite"}, "code": nil, "pre": nil, "kbd": nil,
+			"a":     {"href", "rel", "target"},
+			"img":   {"src", "alt", "width", "height"},
+			"table": nil, "thead": nil, "tbody": nil, "tfoot": nil, "tr": nil,
+			"th": {"colspan", "rowspan", "align"}, "td": {"colspan", "rowspan", "align"},
+		},
+		GlobalAttributes: []string{"title", "lang", "dir"},
+		URLAttributes:    []string{"href", "src", "cite"},
+		URLSchemes:       []string{"http", "https", "mailto"},
+		NoFollow:         true,
+	}
+}
+
+// Sanitizer bawaan untuk function template "sanitize"
+var defaultSanitizer = UGCPolicy()
+
+// Satu atribut hasil tokenisasi
+type sanitizerAttribute struct {
+	Name  string
+	Value string
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Mengecek apakah atribut boleh dipakai di tag tertentu
+func (policy *SanitizerPolicy) allowedAttribute(tag string, name string) bool {
+	if strings.HasPrefix(name, "on") {
+		return false // Event handler (onclick, onerror, ...) tidak pernah diizinkan
+	}
+	if name == "style" {
+		return policy.AllowStyle
+	}
+	return containsString(policy.Tags[tag], name) || containsString(policy.GlobalAttributes, name)
+}
+
+// Mengecek URL: skema harus ada di allowlist, URL relatif diizinkan
+func (policy *SanitizerPolicy) allowedURL(value string) bool {
+	// Spasi dan karakter kontrol dibuang agar "jav\tascript:" tidak lolos
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, value)
+
+	parsed, err := url.Parse(cleaned)
+	if err != nil {
+		return false
+	}
+	if parsed.Scheme == "" {
+		// URL relatif tidak boleh mengandung ":" sebelum "/" (misal "javascript&colon;...")
+		before, _, _ := strings.Cut(cleaned, "/")
+		return !strings.Contains(before, ":")
+	}
+	return containsString(policy.URLSchemes, strings.ToLower(parsed.Scheme))
+}
+
+// Nama tag dan atribut hanya boleh berisi huruf, angka, "-" dan ":"
+func isNameCharacter(character byte) bool {
+	return character == '-' || character == ':' || character == '_' ||
+		(character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z') || (character >= '0' && character <= '9')
+}
+
+// Nama tag harus diawali huruf ASCII, sehingga "<über>" atau "< b>" tetap menjadi teks biasa
+func isTagStartCharacter(character byte) bool {
+	return (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z')
+}
+
+func isSpaceCharacter(character byte) bool {
+	return character == ' ' || character == '\t' || character == '\n' || character == '\r' || character == '\f'
+}
+
+// Membaca atribut tag mulai dari posisi index, berhenti di ">" atau akhir input
+// Mengembalikan atribut, posisi setelah ">", dan apakah tag self-closing
+func readAttributes(input string, index int) ([]sanitizerAttribute, int, bool) {
+	var attributes []sanitizerAttribute
+	selfClosing := false
+
+	for index < len(input) {
+		character := input[index]
+		switch {
+		case isSpaceCharacter(character):
+			index++
+			continue
+		case character == '>':
+			return attributes, index + 1, selfClosing
+		case character == '/':
+			selfClosing = true
+			index++
+			continue
+		}
+		selfClosing = false
+
+		// Nama atribut: semua karakter sampai spasi, "=", ">" atau "/"
+		start := index
+		for index < len(input) && !isSpaceCharacter(input[index]) && input[index] != '=' && input[index] != '>' && input[index] != '/' {
+			index++
+		}
+		name := strings.ToLower(input[start:index])
+		if start == index {
+			// Karakter "=" tanpa nama atribut, dilewati
+			index++
+			continue
+		}
+
+		for index < len(input) && isSpaceCharacter(input[index]) {
+			index++
+		}
+
+		value := ""
+		if index < len(input) && input[index] == '=' {
+			index++
+			for index < len(input) && isSpaceCharacter(input[index]) {
+				index++
+			}
+			if index < len(input) && (input[index] == '"' || input[index] == '\'') {
+				quote := input[index]
+				end := strings.IndexByte(input[index+1:], quote)
+				if end < 0 {
+					value = input[index+1:]
+					index = len(input)
+				} else {
+					value = input[index+1 : index+1+end]
+					index += end + 2
+				}
+			} else {
+				start := index
+				for index < len(input) && !isSpaceCharacter(input[index]) && input[index] != '>' {
+					index++
+				}
+				value = input[start:index]
+			}
+		}
+
+		attributes = append(attributes, sanitizerAttribute{Name: name, Value: html.UnescapeString(value)})
+	}
+	return attributes, index, selfClosing
+}
+
+// Mencari posisi tag penutup </name (tidak peka huruf besar kecil) untuk membuang isi tag
+func skipUntilClosingTag(input string, index int, name string) int {
+	closingTag := "</" + name
+	for position := index; position+len(closingTag) <= len(input); position++ {
+		// EqualFold per posisi agar offset byte tetap sama dengan input asli
+		if !strings.EqualFold(input[position:position+len(closingTag)], closingTag) {
+			continue
+		}
+		closing := strings.IndexByte(input[position:], '>')
+		if closing < 0 {
+			return len(input)
+		}
+		return position + closing + 1
+	}
+	return len(input)
+}
+
+// Menulis tag pembuka yang diizinkan beserta atribut yang lolos pengecekan
+func (policy *SanitizerPolicy) writeStartTag(builder *strings.Builder, tag string, attributes []sanitizerAttribute) {
+	builder.WriteString("<" + tag)
+
+	written := map[string]bool{}
+	targetBlank := false
+	for _, attribute := range attributes {
+		if written[attribute.Name] || !policy.allowedAttribute(tag, attribute.Name) {
+			continue
+		}
+		if containsString(policy.URLAttributes, attribute.Name) && !policy.allowedURL(attribute.Value) {
+			continue
+		}
+		if tag == "a" && attribute.Name == "rel" && policy.NoFollow {
+			continue // rel ditulis ulang di bawah
+		}
+		if attribute.Name == "target" {
+			if attribute.Value != "_blank" {
+				continue
+			}
+			targetBlank = true
+		}
+		written[attribute.Name] = true
+		builder.WriteString(" " + attribute.Name + `="` + html.EscapeString(attribute.Value) + `"`)
+	}
+
+	if tag == "a" && (policy.NoFollow || targetBlank) {
+		rel := []string{}
+		if policy.NoFollow {
+			rel = append(rel, "nofollow")
+		}
+		if targetBlank {
+			rel = append(rel, "noopener", "noreferrer")
+		}
+		builder.WriteString(` rel="` + strings.Join(rel, " ") + `"`)
+	}
+	builder.WriteString(">")
+}
+
+// Membersihkan HTML dari user sesuai policy, hasilnya selalu HTML yang seimbang
+func (policy *SanitizerPolicy) Sanitize(input string) string {
+	input = strings.ReplaceAll(input, "\x00", "")
+
+	builder := new(strings.Builder)
+	var stack []string       // Tag yang sedang terbuka, untuk menutup tag yang lupa ditutup
+	open := map[string]int{} // Jumlah tag terbuka per nama, agar tag penutup tanpa pasangan tidak menelusuri stack
+
+	index := 0
+	for index < len(input) {
+		next := strings.IndexByte(input[index:], '<')
+		if next < 0 {
+			builder.WriteString(html.EscapeString(html.UnescapeString(input[index:])))
+			break
+		}
+		builder.WriteString(html.EscapeString(html.UnescapeString(input[index : index+next])))
+		index += next
+
+		rest := input[index:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			// Komentar HTML dibuang
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				index = len(input)
+			} else {
+				index += 4 + end + 3
+			}
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			// Doctype dan processing instruction dibuang
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				index = len(input)
+			} else {
+				index += end + 1
+			}
+		case strings.HasPrefix(rest, "</") && len(rest) > 2 && isTagStartCharacter(rest[2]):
+			// Tag penutup
+			end := 2
+			for end < len(rest) && isNameCharacter(rest[end]) {
+				end++
+			}
+			tag := strings.ToLower(rest[2:end])
+			closing := strings.IndexByte(rest, '>')
+			if closing < 0 {
+				index = len(input)
+			} else {
+				index += closing + 1
+			}
+
+			// Tag penutup tanpa pasangan dibuang, selain itu tutup tag yang masih terbuka sampai tag yang sesuai
+			if open[tag] == 0 {
+				continue
+			}
+			for {
+				last := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				open[last]--
+				builder.WriteString("</" + last + ">")
+				if last == tag {
+					break
+				}
+			}
+		case len(rest) > 1 && isTagStartCharacter(rest[1]):
+			// Tag pembuka
+			end := 1
+			for end < len(rest) && isNameCharacter(rest[end]) {
+				end++
+			}
+			tag := strings.ToLower(rest[1:end])
+			attributes, next, selfClosing := readAttributes(input, index+end)
+			index = next
+
+			if sanitizerDropContentTags[tag] {
+				if !selfClosing {
+					index = skipUntilClosingTag(input, index, tag)
+				}
+				continue
+			}
+			if _, ok := policy.Tags[tag]; !ok {
+				continue // Tag tidak diizinkan dibuang, isinya tetap ditampilkan sebagai teks
+			}
+
+			policy.writeStartTag(builder, tag, attributes)
+			if !sanitizerVoidTags[tag] {
+				stack = append(stack, tag)
+				open[tag]++
+			}
+		default:
+			// Karakter "<" biasa, misal "1 < 2"
+			builder.WriteString("&lt;")
+			index++
+		}
+	}
+
+	// Menutup tag yang belum ditutup
+	for position := len(stack) - 1; position >= 0; position-- {
+		builder.WriteString("</" + stack[position] + ">")
+	}
+	return builder.String()
+}
+
+// Hasil sanitize ditandai aman sebagai template.HTML
+func (policy *SanitizerPolicy) SanitizeHTML(input string) template.HTML {
+	return template.HTML(policy.Sanitize(input))
+}
+
+// {{sanitize .Body}} menggunakan defaultSanitizer
+func sanitizeHTML(input string) template.HTML {
+	return defaultSanitizer.SanitizeHTML(input)
+}
+
+// Versi aman dari TemplateXSS: input user dibersihkan sebelum ditandai template.HTML
+func TemplateXSSSanitized(writer http.ResponseWriter, request *http.Request) {
+	Render(writer, request, myTemplates, "post.gohtml", map[string]interface{}{
+		"Title": "Template XSS Sanitized",
+		"Body":  defaultSanitizer.SanitizeHTML(request.URL.Query().Get("body")),
+	})
+}
+
+// Kumpulan vektor XSS yang sudah dikenal
+var xssVectors = []string{
+	`<script>alert(1)</script>`,
+	`<SCRIPT SRC=http://xss.example/xss.js></SCRIPT>`,
+	`<img src=x onerror=alert(1)>`,
+	`<IMG SRC="javascript:alert('XSS');">`,
+	`<IMG SRC=JaVaScRiPt:alert('XSS')>`,
+	`<img src=x:alert(alt) onerror=eval(src) alt=0>`,
+	`<a href="jav&#x09;ascript:alert(1)">x</a>`,
+	`<a href="&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)">x</a>`,
+	`<a href="javascript&colon;alert(1)">x</a>`,
+	`<a href="  JAVASCRIPT:alert(1)">x</a>`,
+	`<a href="vbscript:msgbox(1)">x</a>`,
+	`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+	`<svg onload=alert(1)>`,
+	`<svg><script>alert(1)</script></svg>`,
+	`<math><mi xlink:href="javascript:alert(1)">x</mi></math>`,
+	`<iframe src="javascript:alert(1)"></iframe>`,
+	`<object data="javascript:alert(1)"></object>`,
+	`<embed src="javascript:alert(1)">`,
+	`<div style="background:url(javascript:alert(1))">x</div>`,
+	`<p style="width: expression(alert(1))">x</p>`,
+	`<style>body{background:url("javascript:alert(1)")}</style>`,
+	`<<script>script>alert(1)<</script>/script>`,
+	`<scr<script>ipt>alert(1)</script>`,
+	`<!--<script>alert(1)</script>-->`,
+	`"><script>alert(1)</script>`,
+	`<body onload=alert(1)>`,
+	`<form action="javascript:alert(1)"><input type="submit"></form>`,
+	`<base href="javascript:alert(1)//">`,
+	`<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`,
+	`<a href="https://example.com" onclick="alert(1)" onmouseover=alert(1)>ok</a>`,
+	`<img/src="x"/onerror="alert(1)">`,
+	`<a href=javascript:alert(1)>x</a>`,
+	`<details open ontoggle=alert(1)>`,
+	`<video><source onerror="alert(1)"></video>`,
+	"<img src=\"x\" \x00onerror=\"alert(1)\">",
+	`<textarea><script>alert(1)</script></textarea>`,
+	`<a href="https://example.com/" target="_blank">ok</a>`,
+}
+
+// Potongan yang tidak boleh muncul di hasil sanitize
+var xssForbidden = []string{
+	"<script", "<iframe", "<object", "<embed", "<svg", "<math", "<style", "<form", "<base", "<meta", "<body",
+	`="javascript:`, `="vbscript:`, `="data:`, "style=",
+}
+
+func TestSanitizerXSSVectors(t *testing.T) {
+	for _, vector := range xssVectors {
+		result := defaultSanitizer.Sanitize(vector)
+		fmt.Printf("%s\n  => %s\n", vector, result)
+
+		// Teks yang sudah di-escape (misal "onerror=..." di luar tag) tetap aman,
+		// yang dicek hanya tag dan atribut yang benar-benar dihasilkan
+		lower := strings.ToLower(result)
+		for _, forbidden := range xssForbidden {
+			if strings.Contains(lower, forbidden) {
+				t.Errorf("%q menghasilkan %q yang mengandung %q", vector, result, forbidden)
+			}
+		}
+		if containsEventAttribute(lower) {
+			t.Errorf("%q menghasilkan atribut event: %q", vector, result)
+		}
+	}
+}
+
+// Mengecek apakah ada atribut event handler di dalam tag hasil sanitize
+func containsEventAttribute(result string) bool {
+	for _, part := range strings.Split(result, "<")[1:] {
+		tag, _, _ := strings.Cut(part, ">")
+		for _, field := range strings.Fields(tag) {
+			if strings.HasPrefix(field, "on") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestSanitizerExpected(t *testing.T) {
+	tests := []struct {
+		policy   *SanitizerPolicy
+		input    string
+		expected string
+	}{
+		{UGCPolicy(), `<p>Hello <b>World</b></p>`, `<p>Hello <b>World</b></p>`},
+		{UGCPolicy(), `<p>unclosed <b>bold`, `<p>unclosed <b>bold</b></p>`},
+		{UGCPolicy(), `<a href="https://example.com" onclick="alert(1)">ok</a>`, `<a href="https://example.com" rel="nofollow">ok</a>`},
+		{UGCPolicy(), `<a href="/relative/path?a=1&amp;b=2">ok</a>`, `<a href="/relative/path?a=1&amp;b=2" rel="nofollow">ok</a>`},
+		{UGCPolicy(), `<img src="javascript:alert(1)" alt="foto">`, `<img alt="foto">`},
+		{UGCPolicy(), `<div style="color:red" title="info">x</div>`, `<div title="info">x</div>`},
+		{UGCPolicy(), `1 < 2 & 3 > 2`, `1 &lt; 2 &amp; 3 &gt; 2`},
+		{UGCPolicy(), `<unknown>teks</unknown>`, `teks`},
+		{UGCPolicy(), `<über>teks</über>`, `&lt;über&gt;teks&lt;/über&gt;`},
+		{UGCPolicy(), `<p><b>tebal</i> biasa</p>`, `<p><b>tebal biasa</b></p>`},
+		{UGCPolicy(), `<script>alert(1)</script>aman`, `aman`},
+		{StrictPolicy(), `<p>Hello <b>World</b></p>`, `Hello World`},
+	}
+
+	for _, test := range tests {
+		result := test.policy.Sanitize(test.input)
+		if result != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.input, test.expected, result)
+		}
+	}
+}
+
+func TestSanitizeTemplateFunction(t *testing.T) {
+	tmpl := template.Must(template.New("SANITIZE").Funcs(StandardFuncs()).Parse(`<div>{{sanitize .}}</div>`))
+
+	builder := new(strings.Builder)
+	err := tmpl.Execute(builder, `<p>Halo <em>dunia</em></p><img src=x onerror=alert(1)>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(builder.String())
+
+	// Tag yang diizinkan tetap ada, attribute event handler dibuang
+	if builder.String() != `<div><p>Halo <em>dunia</em></p><img src="x"></div>` {
+		t.Errorf("unexpected sanitized output %q", builder.String())
+	}
+	if strings.Contains(builder.String(), "onerror") {
+		t.Error("onerror attribute was not removed")
+	}
+}
+
+func TestTemplateXSSSanitized(t *testing.T) {
+	request := httptest.NewRequest(
+		http.MethodGet,
+		"http://localhost:8080?body="+url.QueryEscape(`<p>alert</p><script>alert('Anda di Hack')</script>`),
+		nil,
+	)
+	recorder := httptest.NewRecorder()
+
+	TemplateXSSSanitized(recorder, request)
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+
+	// Paragraf tetap dirender sebagai HTML, script dibuang seluruhnya
+	if !strings.Contains(string(body), "<p>alert</p>") {
+		t.Errorf("expected allowed HTML in body %q", body)
+	}
+	if strings.Contains(string(body), "<script") || strings.Contains(string(body), "Anda di Hack") {
+		t.Errorf("script leaked into body %q", body)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat sanitizer HTML berbasis allowlist dengan tokenizer buatan sendiri, sehingga konten rich text dari user dapat ditampilkan dengan aman tanpa menggunakan template.HTML secara langsung seperti pada TemplateXSS. Hanya tag, atribut, dan skema URL yang diizinkan policy yang dipertahankan, atribut event dan style dibuang, link diberi rel="nofollow", dan tag seperti script beserta isinya dihapus. Function template "sanitize" mengembalikan template.HTML hanya setelah proses pembersihan, dan kumpulan vektor XSS yang sudah dikenal dipakai sebagai test.
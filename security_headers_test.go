@@ -0,0 +1,462 @@
+package belajar_golang_web
+
+import (
+	"context"           // Untuk menyimpan nonce di context request
+	"crypto/rand"       // Untuk membuat nonce acak
+	"encoding/base64"   // Untuk encode nonce
+	"encoding/json"     // Untuk membaca laporan pelanggaran CSP
+	"errors"            // Untuk mengenali error body terlalu besar
+	"fmt"               // Untuk logging dan output ke console
+	"io"                // Untuk membaca body response
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"strings"           // Untuk menyusun header CSP
+	"testing"           // Untuk unit testing
+)
+
+// Satu directive CSP, misal script-src 'self'
+type CSPDirective struct {
+	Name    string
+	Sources []string
+}
+
+// Directive yang otomatis mendapatkan 'nonce-...' di setiap request
+var cspNonceDirectives = map[string]bool{
+	"script-src": true,
+	"style-src":  true,
+}
+
+// Middleware security headers: Content-Security-Policy dengan nonce baru di setiap request,
+// ditambah X-Content-Type-Options, Referrer-Policy, dan Permissions-Policy
+// Handler tetap bisa menimpa header apa pun karena header ditulis sebelum handler dijalankan
+type SecurityHeaders struct {
+	Handler           http.Handler
+	Directives        []CSPDirective // Directive CSP sesuai urutan
+	FrameAncestors    string         // Siapa yang boleh memasang halaman di iframe, tetap diblokir walaupun ReportOnly
+	ReportOnly        bool           // true = pelanggaran hanya dilaporkan, tidak diblokir
+	ReportURI         string         // Endpoint penerima laporan, misal /csp-report
+	ReferrerPolicy    string
+	PermissionsPolicy string
+}
+
+// Membuat SecurityHeaders dengan nilai default yang aman
+func NewSecurityHeaders(handler http.Handler) *SecurityHeaders {
+	return &SecurityHeaders{
+		Handler: handler,
+		Directives: []CSPDirective{
+			{Name: "default-src", Sources: []string{"'self'"}},
+			{Name: "script-src", Sources: []string{"'self'"}},
+			{Name: "style-src", Sources: []string{"'self'"}},
+			{Name: "img-src", Sources: []string{"'self'", "data:"}},
+			{Name: "object-src", Sources: []string{"'none'"}},
+			{Name: "base-uri", Sources: []string{"'self'"}},
+			{Name: "form-action", Sources: []string{"'self'"}},
+		},
+		FrameAncestors:    "'none'",
+		ReportURI:         "/csp-report",
+		ReferrerPolicy:    "strict-origin-when-cross-origin",
+		PermissionsPolicy: "camera=(), microphone=(), geolocation=(), payment=()",
+	}
+}
+
+// Key context untuk nonce CSP
+type cspNonceKey struct{}
+
+// Membuat nonce acak 128 bit
+// Memakai base64 URL-safe karena html/template meng-escape "+" menjadi "&#43;" di atribut nonce
+func newCSPNonce() string {
+	nonce := make([]byte, 16)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(nonce)
+}
+
+// Mengambil nonce CSP milik request, string kosong jika request tidak melewati SecurityHeaders
+func CSPNonce(request *http.Request) string {
+	if request == nil {
+		return ""
+	}
+	nonce, _ := request.Context().Value(cspNonceKey{}).(string)
+	return nonce
+}
+
+// X-Frame-Options untuk browser lama yang belum mengenal frame-ancestors
+// Daftar origin lain tidak bisa dinyatakan dengan X-Frame-Options, sehingga dikosongkan
+func xFrameOptions(frameAncestors string) string {
+	switch frameAncestors {
+	case "'none'":
+		return "DENY"
+	case "'self'":
+		return "SAMEORIGIN"
+	}
+	return ""
+}
+
+// Menyusun nilai header Content-Security-Policy untuk satu nonce
+func (middleware *SecurityHeaders) policy(nonce string) string {
+	var directives []string
+	for _, directive := range middleware.Directives {
+		sources := directive.Sources
+		if cspNonceDirectives[directive.Name] {
+			sources = append(sources[:len(sources):len(sources)], "'nonce-"+nonce+"'")
+		}
+		directives = append(directives, directive.Name+" "+strings.Join(sources, " "))
+	}
+	if middleware.FrameAncestors != "" {
+		directives = append(directives, "frame-ancestors "+middleware.FrameAncestors)
+	}
+	if middleware.ReportURI != "" {
+		directives = append(directives, "report-uri "+middleware.ReportURI, "report-to csp-endpoint")
+	}
+	return strings.Join(directives, "; ")
+}
+
+func (middleware *SecurityHeaders) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	nonce := newCSPNonce()
+
+	header := writer.Header()
+	if middleware.ReportOnly {
+		header.Set("Content-Security-Policy-Report-Only", middleware.policy(nonce))
+		// Browser mengabaikan frame-ancestors di header Report-Only,
+		// sehingga perlindungan iframe tetap dikirim sebagai policy yang diblokir
+		if middleware.FrameAncestors != "" {
+			header.Set("Content-Security-Policy", "frame-ancestors "+middleware.FrameAncestors)
+		}
+	} else {
+		header.Set("Content-Security-Policy", middleware.policy(nonce))
+	}
+	if frameOptions := xFrameOptions(middleware.FrameAncestors); frameOptions != "" {
+		header.Set("X-Frame-Options", frameOptions)
+	}
+	if middleware.ReportURI != "" {
+		header.Set("Reporting-Endpoints", `csp-endpoint="`+middleware.ReportURI+`"`)
+	}
+	header.Set("X-Content-Type-Options", "nosniff")
+	if middleware.ReferrerPolicy != "" {
+		header.Set("Referrer-Policy", middleware.ReferrerPolicy)
+	}
+	if middleware.PermissionsPolicy != "" {
+		header.Set("Permissions-Policy", middleware.PermissionsPolicy)
+	}
+
+	ctx := context.WithValue(request.Context(), cspNonceKey{}, nonce)
+	middleware.Handler.ServeHTTP(writer, request.WithContext(ctx))
+}
+
+// {{cspNonce .Request}} untuk atribut nonce di tag <script> dan <style>
+func cspNonceFunc(request *http.Request) string {
+	return CSPNonce(request)
+}
+
+// Laporan pelanggaran CSP, format report-uri maupun Reporting API
+type CSPReport struct {
+	DocumentURI        string `json:"document-uri"`
+	BlockedURI         string `json:"blocked-uri"`
+	ViolatedDirective  string `json:"violated-directive"`
+	EffectiveDirective string `json:"effective-directive"`
+	SourceFile         string `json:"source-file"`
+	LineNumber         int    `json:"line-number"`
+	Disposition        string `json:"disposition"`
+}
+
+// Body laporan dari Reporting API memakai camelCase
+type cspReportingBody struct {
+	DocumentURL        string `json:"documentURL"`
+	BlockedURL         string `json:"blockedURL"`
+	EffectiveDirective string `json:"effectiveDirective"`
+	SourceFile         string `json:"sourceFile"`
+	LineNumber         int    `json:"lineNumber"`
+	Disposition        string `json:"disposition"`
+}
+
+// Batas ukuran body laporan agar endpoint tidak bisa dibanjiri data
+const maxCSPReportSize = 64 << 10
+
+// Membaca laporan dari body request
+// application/csp-report: {"csp-report": {...}}
+// application/reports+json: [{"type": "csp-violation", "body": {...}}]
+func parseCSPReports(body []byte) ([]CSPReport, error) {
+	var legacy struct {
+		Report *CSPReport `json:"csp-report"`
+	}
+	if err := json.Unmarshal(body, &legacy); err == nil && legacy.Report != nil {
+		return []CSPReport{*legacy.Report}, nil
+	}
+
+	var reports []struct {
+		Type string           `json:"type"`
+		Body cspReportingBody `json:"body"`
+	}
+	err := json.Unmarshal(body, &reports)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []CSPReport
+	for _, report := range reports {
+		if report.Type != "csp-violation" {
+			continue
+		}
+		result = append(result, CSPReport{
+			DocumentURI:        report.Body.DocumentURL,
+			BlockedURI:         report.Body.BlockedURL,
+			ViolatedDirective:  report.Body.EffectiveDirective,
+			EffectiveDirective: report.Body.EffectiveDirective,
+			SourceFile:         report.Body.SourceFile,
+			LineNumber:         report.Body.LineNumber,
+			Disposition:        report.Body.Disposition,
+		})
+	}
+	return result, nil
+}
+
+// Endpoint /csp-report: mencatat laporan pelanggaran CSP ke log
+func CSPReportHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.Header().Set("Allow", http.MethodPost)
+		http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, maxCSPReportSize))
+	if err != nil {
+		// Hanya body yang melebihi batas yang dibalas 413, error baca lain adalah request rusak
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(writer, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(writer, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		}
+		return
+	}
+
+	reports, err := parseCSPReports(body)
+	if err != nil {
+		http.Error(writer, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	// Isi laporan berasal dari client, %q mencegah baris baru memalsukan baris log
+	for _, report := range reports {
+		fmt.Printf("CSP violation : document=%q blocked=%q directive=%q source=%q:%d disposition=%q\n",
+			report.DocumentURI, report.BlockedURI, report.EffectiveDirective, report.SourceFile, report.LineNumber, report.Disposition)
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// View model untuk csp.gohtml
+type CSPPage struct {
+	Title   string
+	Request *http.Request
+}
+
+func TemplateCSP(writer http.ResponseWriter, request *http.Request) {
+	Render(writer, request, myTemplates, "csp.gohtml", CSPPage{
+		Title:   "Content Security Policy",
+		Request: request,
+	})
+}
+
+// Router contoh: semua halaman dilindungi SecurityHeaders, termasuk TemplateXSS
+func securityHeadersMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", TemplateCSP)
+	mux.HandleFunc("/xss", TemplateXSS)
+	mux.HandleFunc("/csp-report", CSPReportHandler)
+	return mux
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	handler := NewSecurityHeaders(securityHeadersMux())
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/", nil)
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+
+	for _, name := range []string{"Content-Security-Policy", "X-Content-Type-Options", "Referrer-Policy", "Permissions-Policy", "Reporting-Endpoints"} {
+		fmt.Println(name, ":", response.Header.Get(name))
+	}
+	fmt.Println(string(body))
+
+	// Nonce di template harus sama dengan nonce di header
+	policy := response.Header.Get("Content-Security-Policy")
+	start := strings.Index(policy, "'nonce-")
+	if start < 0 {
+		t.Fatal("nonce tidak ada di header CSP")
+	}
+	nonce := policy[start+len("'nonce-"):]
+	nonce = nonce[:strings.Index(nonce, "'")]
+	if strings.Count(string(body), `nonce="`+nonce+`"`) != 2 {
+		t.Errorf("nonce %s tidak dipakai di template", nonce)
+	}
+}
+
+func TestSecurityHeadersNonceUnique(t *testing.T) {
+	handler := NewSecurityHeaders(securityHeadersMux())
+
+	nonces := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:8080/", nil))
+		nonces[recorder.Header().Get("Content-Security-Policy")] = true
+	}
+	if len(nonces) != 3 {
+		t.Errorf("expected 3 nonce berbeda, got %d", len(nonces))
+	}
+}
+
+func TestSecurityHeadersReportOnly(t *testing.T) {
+	handler := NewSecurityHeaders(securityHeadersMux())
+	handler.ReportOnly = true
+	handler.FrameAncestors = "'self' https://partner.example.com"
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:8080/xss", nil))
+
+	fmt.Println("Content-Security-Policy :", recorder.Header().Get("Content-Security-Policy"))
+	fmt.Println("Content-Security-Policy-Report-Only :", recorder.Header().Get("Content-Security-Policy-Report-Only"))
+
+	// Script hanya dilaporkan, tetapi frame-ancestors tetap diblokir
+	if !strings.Contains(recorder.Header().Get("Content-Security-Policy-Report-Only"), "script-src") {
+		t.Error("report-only policy should contain script-src")
+	}
+	if recorder.Header().Get("Content-Security-Policy") != "frame-ancestors 'self' https://partner.example.com" {
+		t.Errorf("unexpected enforced policy %q", recorder.Header().Get("Content-Security-Policy"))
+	}
+}
+
+func TestSecurityHeadersFrameOptions(t *testing.T) {
+	tests := []struct {
+		frameAncestors string
+		reportOnly     bool
+		frameOptions   string
+	}{
+		{"'none'", false, "DENY"},
+		{"'none'", true, "DENY"},
+		{"'self'", true, "SAMEORIGIN"},
+		{"'self' https://partner.example.com", false, ""},
+	}
+
+	for _, test := range tests {
+		handler := NewSecurityHeaders(securityHeadersMux())
+		handler.FrameAncestors = test.frameAncestors
+		handler.ReportOnly = test.reportOnly
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:8080/", nil))
+
+		frameOptions := recorder.Header().Get("X-Frame-Options")
+		fmt.Println(test.frameAncestors, test.reportOnly, frameOptions)
+		if frameOptions != test.frameOptions {
+			t.Errorf("%s (report-only %v): expected X-Frame-Options %q, got %q", test.frameAncestors, test.reportOnly, test.frameOptions, frameOptions)
+		}
+	}
+}
+
+func TestCSPReportHandler(t *testing.T) {
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"csp-report": {"document-uri": "http://localhost:8080/xss", "blocked-uri": "inline", "violated-directive": "script-src", "effective-directive": "script-src", "source-file": "http://localhost:8080/xss", "line-number": 9, "disposition": "enforce"}}`, http.StatusNoContent},
+		{`[{"type": "csp-violation", "body": {"documentURL": "http://localhost:8080/", "blockedURL": "https://evil.example.com/x.js", "effectiveDirective": "script-src-elem", "disposition": "report"}}]`, http.StatusNoContent},
+		{`bukan json`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodPost, "http://localhost:8080/csp-report", strings.NewReader(test.body))
+		request.Header.Set("Content-Type", "application/csp-report")
+		recorder := httptest.NewRecorder()
+
+		CSPReportHandler(recorder, request)
+
+		fmt.Println(recorder.Result().StatusCode)
+		if recorder.Result().StatusCode != test.status {
+			t.Errorf("expected %d, got %d for %s", test.status, recorder.Result().StatusCode, test.body)
+		}
+	}
+}
+
+func TestParseCSPReports(t *testing.T) {
+	// Format lama application/csp-report
+	reports, err := parseCSPReports([]byte(`{"csp-report": {"document-uri": "http://localhost:8080/xss", "blocked-uri": "inline", "violated-directive": "script-src", "effective-directive": "script-src", "source-file": "http://localhost:8080/xss", "line-number": 9, "disposition": "enforce"}}`))
+	fmt.Printf("%+v %v\n", reports, err)
+	expected := CSPReport{
+		DocumentURI:        "http://localhost:8080/xss",
+		BlockedURI:         "inline",
+		ViolatedDirective:  "script-src",
+		EffectiveDirective: "script-src",
+		SourceFile:         "http://localhost:8080/xss",
+		LineNumber:         9,
+		Disposition:        "enforce",
+	}
+	if err != nil || len(reports) != 1 || reports[0] != expected {
+		t.Errorf("unexpected legacy report %+v (%v)", reports, err)
+	}
+
+	// Format Reporting API, laporan selain csp-violation diabaikan
+	reports, err = parseCSPReports([]byte(`[{"type": "deprecation", "body": {}}, {"type": "csp-violation", "body": {"documentURL": "http://localhost:8080/", "blockedURL": "https://evil.example.com/x.js", "effectiveDirective": "script-src-elem", "sourceFile": "http://localhost:8080/app.js", "lineNumber": 3, "disposition": "report"}}]`))
+	fmt.Printf("%+v %v\n", reports, err)
+	expected = CSPReport{
+		DocumentURI:        "http://localhost:8080/",
+		BlockedURI:         "https://evil.example.com/x.js",
+		ViolatedDirective:  "script-src-elem",
+		EffectiveDirective: "script-src-elem",
+		SourceFile:         "http://localhost:8080/app.js",
+		LineNumber:         3,
+		Disposition:        "report",
+	}
+	if err != nil || len(reports) != 1 || reports[0] != expected {
+		t.Errorf("unexpected Reporting API report %+v (%v)", reports, err)
+	}
+}
+
+// Body yang gagal dibaca di tengah jalan, misal koneksi client terputus
+type brokenReader struct{}
+
+func (brokenReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestCSPReportHandlerBodyErrors(t *testing.T) {
+	tests := []struct {
+		body   io.Reader
+		status int
+	}{
+		{strings.NewReader(strings.Repeat("a", maxCSPReportSize+1)), http.StatusRequestEntityTooLarge},
+		{brokenReader{}, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodPost, "http://localhost:8080/csp-report", test.body)
+		recorder := httptest.NewRecorder()
+
+		CSPReportHandler(recorder, request)
+
+		fmt.Println(recorder.Result().StatusCode)
+		if recorder.Result().StatusCode != test.status {
+			t.Errorf("expected %d, got %d", test.status, recorder.Result().StatusCode)
+		}
+	}
+}
+
+func TestSecurityHeadersServer(t *testing.T) {
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: NewSecurityHeaders(securityHeadersMux()),
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat middleware SecurityHeaders yang mengirim Content-Security-Policy dengan nonce acak baru di setiap request, sehingga script hasil injeksi seperti pada TemplateXSS tidak dijalankan browser walaupun lolos dari template. Nonce disimpan di context request dan dipakai di template lewat function "cspNonce" pada tag <script> dan <style>. Mode report-only, endpoint /csp-report yang mencatat laporan pelanggaran ke log, serta header X-Content-Type-Options, Referrer-Policy, frame-ancestors, dan Permissions-Policy dengan nilai default yang dapat diubah juga tersedia. Karena browser mengabaikan frame-ancestors di mode report-only, directive tersebut tetap dikirim sebagai policy yang diblokir, dan X-Frame-Options ditambahkan untuk browser lama jika nilainya 'none' atau 'self'.
@@ -1,8 +1,6 @@
 package belajar_golang_web // Nama package sesuai modul atau folder project
 
 import (
-	_ "embed"      // Digunakan untuk mengaktifkan directive //go:embed
-	"fmt"          // Digunakan untuk menulis output ke ResponseWriter
 	"net/http"     // Package standar untuk membuat HTTP server dan handler
 	"testing"      // Package untuk menjalankan fungsi test di Go
 )
@@ -32,22 +30,16 @@ func TestServeFileServer(t *testing.T) {
 	}
 }
 
-//go:embed resources/ok.html
-// Menyematkan isi file ok.html ke dalam binary sebagai string
-var resourceOk string
-
-//go:embed resources/notfound.html
-// Menyematkan isi file notfound.html ke dalam binary sebagai string
-var resourceNotFound string
-
+// File ok.html dan notfound.html diambil dari folder resources yang di-embed (variabel resources),
+// lewat overlayResources sehingga file di $OVERLAY_DIR/resources bisa menimpa versi embed
 func ServeFileEmbed(writer http.ResponseWriter, request *http.Request) {
 	// Mengecek apakah query parameter "name" ada dan tidak kosong
 	if request.URL.Query().Get("name") != "" {
-		// Menulis langsung konten HTML dari hasil embed ke response
-		fmt.Fprint(writer, resourceOk)
+		// Mengirim ok.html dari embed (atau override di disk) ke response
+		http.ServeFileFS(writer, request, overlayResources, "ok.html")
 	} else {
-		// Menulis konten HTML notfound dari hasil embed ke response
-		fmt.Fprint(writer, resourceNotFound)
+		// Mengirim notfound.html dari embed (atau override di disk) ke response
+		http.ServeFileFS(writer, request, overlayResources, "notfound.html")
 	}
 }
 
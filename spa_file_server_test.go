@@ -0,0 +1,166 @@
+package belajar_golang_web
+
+import (
+	"fmt"               // Untuk menampilkan output ke console
+	"io"                // Untuk membaca body response
+	"io/fs"             // Abstraksi filesystem (embed maupun disk)
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"os"                // Untuk membuat filesystem dari folder disk
+	"path"              // Untuk ekstensi dan pembersihan path
+	"strings"           // Untuk manipulasi string
+	"testing"           // Untuk unit testing
+)
+
+// Static file server dengan mode Single Page Application (SPA):
+// path yang terlihat seperti route client (tanpa ekstensi, Accept: text/html)
+// dilayani dengan index.html, sedangkan asset yang hilang tetap 404
+type SPAHandler struct {
+	FileSystem  fs.FS        // Sumber file: embed.FS atau os.DirFS
+	Handler     http.Handler // Handler file statis, misal StaticHandler atau http.FileServer
+	Index       string       // File yang dikirim untuk route client, default "index.html"
+	APIPrefixes []string     // Prefix path yang tidak boleh fallback, misal "/api/"
+}
+
+// Membuat SPAHandler di atas StaticHandler agar ETag dan Cache-Control tetap berlaku
+func NewSPAHandler(fileSystem fs.FS, policies ...StaticCachePolicy) (*SPAHandler, error) {
+	static, err := NewStaticHandler(fileSystem, policies...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SPAHandler{
+		FileSystem:  fileSystem,
+		Handler:     static,
+		Index:       "index.html",
+		APIPrefixes: []string{"/api/"},
+	}, nil
+}
+
+// Mengecek apakah request merupakan route client yang boleh fallback ke index.html
+func (handler *SPAHandler) isClientRoute(request *http.Request) bool {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		return false
+	}
+
+	// Path API selalu mendapat 404 asli
+	for _, prefix := range handler.APIPrefixes {
+		if strings.HasPrefix(request.URL.Path, prefix) {
+			return false
+		}
+	}
+
+	// Path dengan ekstensi dianggap asset (index.css, logo.png)
+	if path.Ext(request.URL.Path) != "" {
+		return false
+	}
+
+	// Hanya browser yang meminta HTML, bukan fetch/XHR untuk JSON
+	return strings.Contains(request.Header.Get("Accept"), "text/html")
+}
+
+// Implementasi http.Handler
+func (handler *SPAHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+request.URL.Path), "/")
+	if name == "" {
+		name = "."
+	}
+
+	// File atau folder yang ada dilayani seperti biasa
+	_, err := fs.Stat(handler.FileSystem, name)
+	if err == nil || !handler.isClientRoute(request) {
+		handler.Handler.ServeHTTP(writer, request)
+		return
+	}
+
+	// Route client seperti /dashboard/settings dijawab dengan isi index.html
+	// index.html selalu direvalidasi agar client mendapat versi asset terbaru
+	writer.Header().Set("Cache-Control", "no-cache")
+	http.ServeFileFS(writer, request, handler.FileSystem, handler.Index)
+}
+
+func TestSPAHandlerEmbed(t *testing.T) {
+	directory, _ := fs.Sub(resources, "resources")
+	handler, err := NewSPAHandler(directory, staticCachePolicies...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", handler))
+
+	tests := []struct {
+		url    string
+		accept string
+		status int
+		body   string // Potongan isi yang diharapkan, kosong berarti tidak dicek
+	}{
+		{"/static/dashboard/settings", "text/html,application/xhtml+xml", http.StatusOK, "<h1>Hello</h1>"}, // Route client -> index.html
+		{"/static/dashboard/settings", "application/json", http.StatusNotFound, ""},                        // Bukan browser -> 404
+		{"/static/missing.js", "text/html", http.StatusNotFound, ""},                                       // Asset hilang -> 404
+		{"/static/api/users", "text/html", http.StatusNotFound, ""},                                        // Path API -> 404
+		{"/static/index.css", "text/css", http.StatusOK, ""},                                               // Asset ada -> 200
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+test.url, nil)
+		request.Header.Set("Accept", test.accept)
+		recorder := httptest.NewRecorder()
+
+		mux.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(test.url, test.accept, response.StatusCode, response.Header.Get("Content-Type"))
+
+		if response.StatusCode != test.status {
+			t.Errorf("%s (Accept %s): expected %d, got %d", test.url, test.accept, test.status, response.StatusCode)
+		}
+		if test.body != "" && !strings.Contains(string(body), test.body) {
+			t.Errorf("%s: expected body containing %q, got %q", test.url, test.body, body)
+		}
+	}
+}
+
+func TestSPAHandlerDisk(t *testing.T) {
+	handler, err := NewSPAHandler(os.DirFS("./resources"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/dashboard/settings", nil)
+	request.Header.Set("Accept", "text/html")
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	fmt.Println(response.StatusCode, response.Header.Get("Content-Type"))
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", response.StatusCode)
+	}
+}
+
+func TestSPAHandlerServer(t *testing.T) {
+	directory, _ := fs.Sub(resources, "resources")
+	handler, err := NewSPAHandler(directory, staticCachePolicies...)
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", handler))
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: mux,
+	}
+
+	err = server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini menambahkan mode Single Page Application pada static file server, di mana deep link seperti /static/dashboard/settings yang tidak memiliki ekstensi dan diminta browser (Accept: text/html) akan dilayani dengan index.html agar routing ditangani oleh frontend. Asset yang tidak ditemukan dan path API tetap mendapatkan 404 asli, dan handler yang sama bekerja untuk embed.FS maupun folder disk.
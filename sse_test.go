@@ -0,0 +1,506 @@
+package belajar_golang_web
+
+import (
+	"bufio"             // Untuk membaca stream event baris per baris di test
+	"context"           // Untuk membatalkan koneksi di test
+	"encoding/json"     // Untuk event berisi JSON
+	"errors"            // Untuk errors.Is
+	"fmt"               // Untuk format event dan output ke console
+	"io"                // Untuk io.Writer
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk server test di port acak
+	"strconv"           // Untuk ID event
+	"strings"           // Untuk manipulasi string
+	"sync"              // Untuk mengamankan daftar subscriber
+	"testing"           // Untuk unit testing
+	"time"              // Untuk heartbeat dan retry
+)
+
+// Satu event Server-Sent Events
+type Event struct {
+	ID    string
+	Topic string // Dipakai broker untuk memilih subscriber, tidak dikirim ke client
+	Name  string // Field "event", didengar di browser dengan addEventListener(name, ...)
+	Data  string
+	Retry time.Duration // Jeda reconnect yang disarankan ke browser, 0 berarti tidak dikirim
+}
+
+// ID atau nama event berisi baris baru, yang akan menyisipkan field palsu ke stream
+var ErrInvalidEvent = errors.New("sse: id dan nama event tidak boleh berisi CR atau LF")
+
+// Pemisah baris text/event-stream: CRLF, CR saja, atau LF saja
+var eventLineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// Memeriksa apakah nilai aman ditulis sebagai satu field id atau event
+func validEventField(value string) bool {
+	return !strings.ContainsAny(value, "\r\n")
+}
+
+// Menulis event dengan format text/event-stream, data multi baris dipecah per baris "data:"
+// Event dengan ID atau Name berisi CR/LF ditolak dengan ErrInvalidEvent tanpa menulis apa pun
+func (event Event) WriteTo(writer io.Writer) (int64, error) {
+	if !validEventField(event.ID) || !validEventField(event.Name) {
+		return 0, ErrInvalidEvent
+	}
+
+	var builder strings.Builder
+	if event.ID != "" {
+		builder.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Name != "" {
+		builder.WriteString("event: " + event.Name + "\n")
+	}
+	if event.Retry > 0 {
+		builder.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(eventLineBreaks.Replace(event.Data), "\n") {
+		builder.WriteString("data: " + line + "\n")
+	}
+	builder.WriteString("\n")
+
+	written, err := io.WriteString(writer, builder.String())
+	return int64(written), err
+}
+
+// Subscriber menerima event lewat channel
+// Channel ditutup jika subscriber terlalu lambat, client lalu reconnect dan mengejar dengan Last-Event-ID
+type subscriber struct {
+	topics []string
+	events chan Event
+}
+
+func (subscriber *subscriber) wants(topic string) bool {
+	return len(subscriber.topics) == 0 || containsString(subscriber.topics, topic)
+}
+
+// Pub/sub untuk Server-Sent Events dengan riwayat terbatas untuk replay
+type Broker struct {
+	HistorySize int           // Jumlah event terakhir yang disimpan untuk Last-Event-ID
+	BufferSize  int           // Kapasitas channel per subscriber
+	Heartbeat   time.Duration // Jeda komentar heartbeat agar proxy tidak menutup koneksi
+	Retry       time.Duration // Jeda reconnect yang disarankan ke browser
+
+	mutex       sync.Mutex
+	nextID      int
+	history     []Event
+	subscribers map[*subscriber]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		HistorySize: 100,
+		BufferSize:  16,
+		Heartbeat:   15 * time.Second,
+		Retry:       3 * time.Second,
+		subscribers: map[*subscriber]struct{}{},
+	}
+}
+
+// Mengirim event ke semua subscriber topic tersebut, ID diberikan oleh broker secara berurutan
+func (broker *Broker) Publish(topic string, name string, data string) Event {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	broker.nextID++
+	event := Event{ID: strconv.Itoa(broker.nextID), Topic: topic, Name: name, Data: data}
+
+	broker.history = append(broker.history, event)
+	if len(broker.history) > broker.HistorySize {
+		broker.history = broker.history[len(broker.history)-broker.HistorySize:]
+	}
+
+	for subscriber := range broker.subscribers {
+		if !subscriber.wants(topic) {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			// Subscriber lambat diputus daripada menahan publisher
+			broker.remove(subscriber)
+		}
+	}
+	return event
+}
+
+// Sama seperti Publish dengan data berupa JSON
+func (broker *Broker) PublishJSON(topic string, name string, value interface{}) (Event, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Event{}, err
+	}
+	return broker.Publish(topic, name, string(data)), nil
+}
+
+// Mendaftarkan subscriber, topics kosong berarti semua topic
+// Event setelah lastEventID dikembalikan sebagai replay; replay dan pendaftaran
+// dilakukan di bawah lock yang sama sehingga tidak ada event yang terlewat
+func (broker *Broker) Subscribe(lastEventID string, topics ...string) ([]Event, <-chan Event, func()) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	subscriber := &subscriber{topics: topics, events: make(chan Event, broker.BufferSize)}
+	broker.subscribers[subscriber] = struct{}{}
+
+	var replay []Event
+	if last, err := strconv.Atoi(lastEventID); err == nil {
+		for _, event := range broker.history {
+			id, _ := strconv.Atoi(event.ID)
+			if id > last && subscriber.wants(event.Topic) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	cancel := func() {
+		broker.mutex.Lock()
+		defer broker.mutex.Unlock()
+		broker.remove(subscriber)
+	}
+	return replay, subscriber.events, cancel
+}
+
+// Harus dipanggil saat mutex sudah dikunci
+func (broker *Broker) remove(subscriber *subscriber) {
+	if _, ok := broker.subscribers[subscriber]; ok {
+		delete(broker.subscribers, subscriber)
+		close(subscriber.events)
+	}
+}
+
+func (broker *Broker) SubscriberCount() int {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	return len(broker.subscribers)
+}
+
+// Memutus semua subscriber, misal saat server dimatikan
+func (broker *Broker) Close() {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	for subscriber := range broker.subscribers {
+		broker.remove(subscriber)
+	}
+}
+
+// Handler SSE untuk topic tertentu, jika Topics kosong topic dibaca dari ?topic=
+func (broker *Broker) Handler(topics ...string) *SSEHandler {
+	return &SSEHandler{Broker: broker, Topics: topics}
+}
+
+// Handler yang mengirim event dari Broker sebagai text/event-stream
+type SSEHandler struct {
+	Broker *Broker
+	Topics []string
+}
+
+func (handler *SSEHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	topics := handler.Topics
+	if len(topics) == 0 {
+		topics = request.URL.Query()["topic"]
+	}
+
+	// Browser mengirim Last-Event-ID saat reconnect, ?lastEventId untuk koneksi pertama
+	lastEventID := request.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = request.URL.Query().Get("lastEventId")
+	}
+
+	// ResponseController menembus pembungkus ResponseWriter yang memiliki Unwrap
+	controller := http.NewResponseController(writer)
+	controller.SetWriteDeadline(time.Time{}) // Stream berjalan lama, WriteTimeout server tidak berlaku
+
+	header := writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // Mematikan buffering di nginx
+	writer.WriteHeader(http.StatusOK)
+
+	replay, events, cancel := handler.Broker.Subscribe(lastEventID, topics...)
+	defer cancel()
+
+	fmt.Fprintf(writer, "retry: %d\n\n", handler.Broker.Retry.Milliseconds())
+	for _, event := range replay {
+		writeEvent(writer, request, event)
+	}
+	err := controller.Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		fmt.Printf("SSE tidak didukung : path=%s error=%v\n", request.URL.Path, err)
+		return
+	}
+
+	heartbeat := time.NewTicker(handler.Broker.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			// Client menutup koneksi, subscriber dilepas oleh cancel
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			err = writeEvent(writer, request, event)
+		case <-heartbeat.C:
+			// Baris yang diawali ":" adalah komentar dan diabaikan browser
+			_, err = io.WriteString(writer, ": heartbeat\n\n")
+		}
+		if err == nil {
+			err = controller.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Menulis satu event ke stream, event yang tidak valid dilewati agar koneksi tetap berjalan
+func writeEvent(writer io.Writer, request *http.Request, event Event) error {
+	_, err := event.WriteTo(writer)
+	if errors.Is(err, ErrInvalidEvent) {
+		fmt.Printf("SSE event dilewati : path=%s id=%q name=%q error=%v\n", request.URL.Path, event.ID, event.Name, err)
+		return nil
+	}
+	return err
+}
+
+// Contoh: halaman mengirim pesan lewat POST /publish dan menerimanya di /events
+func sseMux(broker *Broker) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET /events", broker.Handler())
+	mux.Handle("GET /events/files", broker.Handler("files"))
+	mux.Handle("POST /publish", Handle(func(writer http.ResponseWriter, request *http.Request) error {
+		topic := request.PostFormValue("topic")
+		name := request.PostFormValue("event")
+		var validation ValidationErrors
+		if topic == "" {
+			validation.Add("topic", "wajib diisi")
+		}
+		if !validEventField(name) {
+			validation.Add("event", "tidak boleh berisi baris baru")
+		}
+		if err := validation.Err(); err != nil {
+			return err
+		}
+		event := broker.Publish(topic, name, request.PostFormValue("data"))
+		WriteJSON(writer, request, http.StatusAccepted, map[string]string{"id": event.ID})
+		return nil
+	}))
+	return mux
+}
+
+func TestEventWriteTo(t *testing.T) {
+	var builder strings.Builder
+	Event{ID: "7", Name: "upload", Data: "baris pertama\nbaris kedua", Retry: 2 * time.Second}.WriteTo(&builder)
+	fmt.Print(builder.String())
+
+	expected := "id: 7\nevent: upload\nretry: 2000\ndata: baris pertama\ndata: baris kedua\n\n"
+	if builder.String() != expected {
+		t.Errorf("unexpected event: %q", builder.String())
+	}
+}
+
+func TestEventWriteToLineBreaks(t *testing.T) {
+	// CR saja juga pemisah baris, sehingga harus dipecah agar tidak menjadi field baru
+	var builder strings.Builder
+	Event{Data: "satu\rid: 99\r\ndua\ntiga"}.WriteTo(&builder)
+	fmt.Printf("%q\n", builder.String())
+
+	expected := "data: satu\ndata: id: 99\ndata: dua\ndata: tiga\n\n"
+	if builder.String() != expected {
+		t.Errorf("unexpected event: %q", builder.String())
+	}
+
+	// ID dan Name berisi CR atau LF ditolak tanpa menulis apa pun
+	for _, event := range []Event{
+		{ID: "1\ndata: palsu", Data: "x"},
+		{Name: "upload\rretry: 1", Data: "x"},
+	} {
+		builder.Reset()
+		written, err := event.WriteTo(&builder)
+		fmt.Println(written, err)
+		if !errors.Is(err, ErrInvalidEvent) || builder.Len() != 0 {
+			t.Errorf("expected ErrInvalidEvent for %q, got %v and %q", event, err, builder.String())
+		}
+	}
+}
+
+func TestSSEPublishInvalidEvent(t *testing.T) {
+	broker := NewBroker()
+	body := strings.NewReader("topic=chat&event=message%0Adata:%20palsu&data=halo")
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:8080/publish", body)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+
+	sseMux(broker).ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	responseBody, _ := io.ReadAll(response.Body)
+	fmt.Println(response.StatusCode, string(responseBody))
+	if response.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", response.StatusCode)
+	}
+	if len(broker.history) != 0 {
+		t.Errorf("invalid event was published: %v", broker.history)
+	}
+}
+
+func TestBrokerReplay(t *testing.T) {
+	broker := NewBroker()
+	broker.HistorySize = 3
+
+	broker.Publish("files", "created", "a.png") // 1, sudah keluar dari riwayat
+	broker.Publish("chat", "message", "halo")   // 2
+	broker.Publish("files", "created", "b.png") // 3
+	broker.Publish("files", "deleted", "a.png") // 4
+
+	replay, _, cancel := broker.Subscribe("2", "files")
+	defer cancel()
+
+	for _, event := range replay {
+		fmt.Println(event.ID, event.Topic, event.Name, event.Data)
+	}
+	if len(replay) != 2 || replay[0].ID != "3" || replay[1].ID != "4" {
+		t.Errorf("unexpected replay: %v", replay)
+	}
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+	broker.BufferSize = 2
+
+	_, events, cancel := broker.Subscribe("")
+	defer cancel()
+
+	for index := 0; index < 3; index++ {
+		broker.Publish("files", "created", strconv.Itoa(index))
+	}
+
+	count := 0
+	for range events {
+		count++
+	}
+	fmt.Println("received before disconnect:", count, "subscribers:", broker.SubscriberCount())
+	if count != 2 || broker.SubscriberCount() != 0 {
+		t.Errorf("expected slow subscriber to be dropped after 2 events, got %d", count)
+	}
+}
+
+// Membaca satu blok event (sampai baris kosong) dari stream
+func readEventBlock(reader *bufio.Reader) (string, error) {
+	var block strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return block.String(), err
+		}
+		if line == "\n" {
+			return block.String(), nil
+		}
+		block.WriteString(line)
+	}
+}
+
+func TestSSEHandler(t *testing.T) {
+	broker := NewBroker()
+	broker.Heartbeat = 50 * time.Millisecond
+
+	// Melewati LogMiddleware untuk memastikan flush tetap sampai ke client
+	server := httptest.NewServer(&LogMiddleware{Handler: sseMux(broker)})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events/files", nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	fmt.Println(response.StatusCode, response.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(response.Body)
+	block, _ := readEventBlock(reader)
+	fmt.Printf("%q\n", block)
+
+	broker.Publish("chat", "message", "tidak dikirim ke /events/files")
+	broker.Publish("files", "created\nevent: palsu", "dilewati tanpa memutus stream")
+	broker.Publish("files", "created", "dottore.png")
+
+	block, _ = readEventBlock(reader)
+	fmt.Printf("%q\n", block)
+	if block != "id: 3\nevent: created\ndata: dottore.png\n" {
+		t.Errorf("unexpected event block: %q", block)
+	}
+
+	block, _ = readEventBlock(reader)
+	fmt.Printf("%q\n", block)
+	if block != ": heartbeat\n" {
+		t.Errorf("expected heartbeat, got %q", block)
+	}
+
+	// Setelah client memutus koneksi, subscriber harus dilepas
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for broker.SubscriberCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if broker.SubscriberCount() != 0 {
+		t.Errorf("subscriber not removed after disconnect")
+	}
+}
+
+func TestSSEHandlerLastEventID(t *testing.T) {
+	broker := NewBroker()
+	broker.Publish("files", "created", "a.png")
+	broker.Publish("files", "created", "b.png")
+	broker.Publish("files", "created", "c.png")
+
+	server := httptest.NewServer(sseMux(broker))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?topic=files", nil)
+	request.Header.Set("Last-Event-ID", "1")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	reader := bufio.NewReader(response.Body)
+	readEventBlock(reader) // retry
+
+	for _, expected := range []string{"b.png", "c.png"} {
+		block, _ := readEventBlock(reader)
+		fmt.Printf("%q\n", block)
+		if !strings.Contains(block, "data: "+expected) {
+			t.Errorf("expected replay of %s, got %q", expected, block)
+		}
+	}
+}
+
+func TestSSEServer(t *testing.T) {
+	broker := NewBroker()
+
+	// Contoh event berkala agar stream bisa dilihat dengan curl -N localhost:8080/events
+	go func() {
+		for tick := range time.Tick(2 * time.Second) {
+			broker.Publish("clock", "tick", tick.Format(time.RFC3339))
+		}
+	}()
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: &LogMiddleware{Handler: sseMux(broker)},
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini menambahkan dukungan Server-Sent Events. Broker adalah pub/sub dengan subscription per topic dan riwayat event terbatas, sehingga client yang reconnect dengan Last-Event-ID menerima event yang terlewat tanpa celah karena replay dan pendaftaran dilakukan di bawah lock yang sama. SSEHandler mengirim event sebagai text/event-stream, mengirim komentar heartbeat agar proxy tidak menutup koneksi, melakukan flush lewat http.ResponseController sehingga tetap bekerja melalui pembungkus ResponseWriter seperti middleware logging dan AppHandler, serta melepas subscriber begitu client memutus koneksi lewat context request. Subscriber yang terlalu lambat diputus agar tidak menahan publisher dan akan mengejar lewat replay saat reconnect. Data dipecah pada CRLF, CR, maupun LF, sedangkan ID dan nama event yang berisi baris baru ditolak dengan ErrInvalidEvent agar client tidak bisa menyisipkan field palsu ke stream.
@@ -0,0 +1,278 @@
+package belajar_golang_web
+
+import (
+	"crypto/sha256"     // Untuk menghitung hash isi file
+	"encoding/hex"      // Untuk mengubah hash menjadi string
+	"fmt"               // Untuk menampilkan output ke console
+	"io"                // Untuk membaca isi file
+	"io/fs"             // Abstraksi filesystem (embed maupun disk)
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"os"                // Untuk membuat filesystem dari folder disk
+	"path"              // Untuk mencocokkan pola path
+	"strings"           // Untuk manipulasi string
+	"sync"              // Untuk mengamankan cache hash dari akses bersamaan
+	"testing"           // Untuk unit testing
+	"time"              // Untuk informasi waktu modifikasi file
+)
+
+// Aturan Cache-Control berdasarkan pola path, misal "*.css" atau "images/*"
+type StaticCachePolicy struct {
+	Pattern      string // Pola path.Match terhadap path file (tanpa "/" di depan)
+	CacheControl string // Nilai header Cache-Control untuk file yang cocok
+}
+
+// Informasi hash satu file, disimpan bersama ukuran dan waktu modifikasi
+// agar hash dihitung ulang jika file di disk berubah
+type staticFileHash struct {
+	ETag    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Static file server dengan ETag berbasis hash isi file
+type StaticHandler struct {
+	FileSystem          fs.FS               // Sumber file: embed.FS atau os.DirFS
+	Policies            []StaticCachePolicy // Aturan Cache-Control per pola path
+	DefaultCacheControl string              // Cache-Control jika tidak ada pola yang cocok
+
+	fileServer http.Handler
+	mutex      sync.RWMutex
+	hashes     map[string]staticFileHash
+}
+
+// Membuat StaticHandler dan menghitung hash seluruh file di awal (saat startup)
+func NewStaticHandler(fileSystem fs.FS, policies ...StaticCachePolicy) (*StaticHandler, error) {
+	handler := &StaticHandler{
+		FileSystem:          fileSystem,
+		Policies:            policies,
+		DefaultCacheControl: "no-cache", // Boleh disimpan, tetapi wajib revalidasi dengan ETag
+		fileServer:          http.FileServer(http.FS(fileSystem)),
+		hashes:              map[string]staticFileHash{},
+	}
+
+	err := fs.WalkDir(fileSystem, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		_, err = handler.hash(name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+// Menghitung hash sha256 isi file, hasilnya dipakai sebagai strong ETag
+func computeStaticHash(fileSystem fs.FS, name string) (staticFileHash, error) {
+	file, err := fileSystem.Open(name)
+	if err != nil {
+		return staticFileHash{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return staticFileHash{}, err
+	}
+	if info.IsDir() {
+		return staticFileHash{}, fs.ErrInvalid
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return staticFileHash{}, err
+	}
+
+	return staticFileHash{
+		ETag:    `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// Mengambil hash file dari cache, menghitung ulang jika belum ada atau file berubah
+func (handler *StaticHandler) hash(name string) (staticFileHash, error) {
+	info, err := fs.Stat(handler.FileSystem, name)
+	if err != nil {
+		return staticFileHash{}, err
+	}
+
+	handler.mutex.RLock()
+	cached, ok := handler.hashes[name]
+	handler.mutex.RUnlock()
+
+	if ok && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+		return cached, nil
+	}
+
+	computed, err := computeStaticHash(handler.FileSystem, name)
+	if err != nil {
+		return staticFileHash{}, err
+	}
+
+	handler.mutex.Lock()
+	handler.hashes[name] = computed
+	handler.mutex.Unlock()
+
+	return computed, nil
+}
+
+// Mencari Cache-Control yang sesuai dengan path file
+func (handler *StaticHandler) cacheControl(name string) string {
+	for _, policy := range handler.Policies {
+		matched, _ := path.Match(policy.Pattern, name)
+		if matched {
+			return policy.CacheControl
+		}
+	}
+	return handler.DefaultCacheControl
+}
+
+// Implementasi http.Handler
+func (handler *StaticHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+request.URL.Path), "/")
+
+	hash, err := handler.hash(name)
+	if err == nil {
+		// ETag diset sebelum FileServer dipanggil, sehingga http.ServeContent
+		// otomatis mencocokkan If-None-Match dan membalas 304 Not Modified
+		writer.Header().Set("ETag", hash.ETag)
+		// Cache-Control yang sudah diset handler pembungkus tidak ditimpa
+		if writer.Header().Get("Cache-Control") == "" {
+			writer.Header().Set("Cache-Control", handler.cacheControl(name))
+		}
+	}
+
+	handler.fileServer.ServeHTTP(writer, request)
+}
+
+// Kebijakan cache bawaan untuk contoh di bawah
+var staticCachePolicies = []StaticCachePolicy{
+	{Pattern: "*.css", CacheControl: "public, max-age=3600"},
+	{Pattern: "*.js", CacheControl: "public, max-age=3600"},
+	{Pattern: "*.png", CacheControl: "public, max-age=86400"},
+}
+
+// Tabel perilaku yang harus sama untuk sumber embed maupun disk
+// ifNoneMatch "*" berarti memakai ETag dari response sebelumnya untuk file yang sama
+var staticHandlerCases = []struct {
+	path         string
+	ifNoneMatch  string
+	status       int
+	cacheControl string
+}{
+	{"/index.css", "", http.StatusOK, "public, max-age=3600"},
+	{"/index.css", "*", http.StatusNotModified, "public, max-age=3600"},
+	{"/index.css", `"etag-lain"`, http.StatusOK, "public, max-age=3600"},
+	{"/index.js", "", http.StatusOK, "public, max-age=3600"},
+	{"/index.js", "*", http.StatusNotModified, "public, max-age=3600"},
+	{"/dottore.png", "", http.StatusOK, "public, max-age=86400"},
+	{"/notfound.html", "", http.StatusOK, "no-cache"},
+	{"/tidak-ada.txt", "", http.StatusNotFound, ""},
+}
+
+// Menjalankan staticHandlerCases, mengembalikan ETag per path
+func checkStaticHandler(t *testing.T, handler *StaticHandler) map[string]string {
+	etags := map[string]string{}
+
+	for _, test := range staticHandlerCases {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+test.path, nil)
+		if test.ifNoneMatch == "*" {
+			request.Header.Set("If-None-Match", etags[test.path])
+		} else if test.ifNoneMatch != "" {
+			request.Header.Set("If-None-Match", test.ifNoneMatch)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		etag := response.Header.Get("ETag")
+		fmt.Println(test.path, response.StatusCode, etag, response.Header.Get("Cache-Control"))
+
+		if response.StatusCode != test.status {
+			t.Errorf("%s (If-None-Match %q): expected %d, got %d", test.path, test.ifNoneMatch, test.status, response.StatusCode)
+		}
+		if response.Header.Get("Cache-Control") != test.cacheControl {
+			t.Errorf("%s: expected Cache-Control %q, got %q", test.path, test.cacheControl, response.Header.Get("Cache-Control"))
+		}
+		if test.status == http.StatusNotFound {
+			if etag != "" {
+				t.Errorf("%s: unexpected ETag %s", test.path, etag)
+			}
+			continue
+		}
+		if etag == "" {
+			t.Errorf("%s: missing ETag", test.path)
+		}
+		etags[test.path] = etag
+	}
+	return etags
+}
+
+func TestStaticHandlerEmbed(t *testing.T) {
+	// Filesystem embed dari variabel resources di file_server_test.go
+	directory, _ := fs.Sub(resources, "resources")
+	handler, err := NewStaticHandler(directory, staticCachePolicies...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStaticHandler(t, handler)
+}
+
+func TestStaticHandlerDisk(t *testing.T) {
+	// Variasi disk, perilakunya sama persis dengan versi embed
+	handler, err := NewStaticHandler(os.DirFS("./resources"), staticCachePolicies...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStaticHandler(t, handler)
+}
+
+func TestStaticHandlerSameETag(t *testing.T) {
+	// ETag dihitung dari isi file, sehingga sama untuk embed dan disk
+	directory, _ := fs.Sub(resources, "resources")
+	embedHandler, _ := NewStaticHandler(directory, staticCachePolicies...)
+	diskHandler, _ := NewStaticHandler(os.DirFS("./resources"), staticCachePolicies...)
+
+	etags := []string{}
+	for _, handler := range []*StaticHandler{embedHandler, diskHandler} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:8080/dottore.png", nil))
+		etags = append(etags, recorder.Result().Header.Get("ETag"))
+	}
+	fmt.Println(etags)
+	if etags[0] == "" || etags[0] != etags[1] {
+		t.Errorf("embed ETag %s, disk ETag %s", etags[0], etags[1])
+	}
+}
+
+func TestStaticHandlerServer(t *testing.T) {
+	directory, _ := fs.Sub(resources, "resources")
+	handler, err := NewStaticHandler(directory, staticCachePolicies...)
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", handler))
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: mux,
+	}
+
+	err = server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat static file server yang menghitung hash isi file saat startup dan menggunakannya sebagai strong ETag, karena http.FS dari embed.FS tidak memiliki waktu modifikasi sehingga header Last-Modified tidak pernah dikirim. Dengan ETag yang sudah diset, http.FileServer otomatis membalas 304 Not Modified ketika If-None-Match cocok, dan header Cache-Control dapat diatur per pola path. Handler yang sama bekerja untuk embed.FS maupun folder disk melalui os.DirFS.
@@ -0,0 +1,311 @@
+package belajar_golang_web
+
+import (
+	"fmt"      // Untuk pesan error dan output ke console
+	"net/mail" // Untuk aturan format=email
+	"net/url"  // Untuk mengisi struct dari query string atau body form
+	"reflect"  // Untuk membaca struct tag
+	"strconv"  // Untuk membaca angka pada aturan min dan max
+	"strings"  // Untuk memecah struct tag
+	"testing"  // Untuk unit testing
+)
+
+// Aturan validasi dari struct tag, misal `validate:"required,min=1,max=150,format=email,oneof=a b"`
+// Aturan yang sama dipakai untuk validasi dan untuk schema OpenAPI
+type fieldRules struct {
+	Required bool
+	Min      *float64 // Nilai minimum untuk angka, panjang minimum untuk string dan slice
+	Max      *float64
+	Format   string   // Saat ini yang divalidasi hanya "email"
+	Enum     []string // Nilai yang diperbolehkan
+}
+
+func parseValidateTag(tag string) fieldRules {
+	var rules fieldRules
+	for _, rule := range strings.Split(tag, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			rules.Required = true
+		case "min", "max":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			if name == "min" {
+				rules.Min = &number
+			} else {
+				rules.Max = &number
+			}
+		case "format":
+			rules.Format = value
+		case "oneof":
+			rules.Enum = strings.Fields(value)
+		}
+	}
+	return rules
+}
+
+// Nama field sesuai tag json, false jika field tidak ikut di-encode
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// Nama field sesuai tag form atau query, false jika field tidak memiliki tag tersebut
+func valuesFieldName(tag string) func(field reflect.StructField) (string, bool) {
+	return func(field reflect.StructField) (string, bool) {
+		name := field.Tag.Get(tag)
+		return name, field.IsExported() && name != "" && name != "-"
+	}
+}
+
+// Memvalidasi struct berdasarkan tag validate, hasilnya ValidationErrors (422) atau nil
+func ValidateStruct(value interface{}) error {
+	return validateStruct(value, jsonFieldName)
+}
+
+// Sama seperti ValidateStruct, nama field pada pesan error diambil dengan fieldName
+func validateStruct(value interface{}, fieldName func(field reflect.StructField) (string, bool)) error {
+	structValue := reflect.Indirect(reflect.ValueOf(value))
+	if structValue.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var validation ValidationErrors
+	for index := 0; index < structValue.NumField(); index++ {
+		field := structValue.Type().Field(index)
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		for _, message := range checkFieldRules(structValue.Field(index), parseValidateTag(tag)) {
+			validation.Add(name, message)
+		}
+	}
+	return validation.Err()
+}
+
+// Mengisi struct dari url.Values berdasarkan tag, misal tag "form" untuk `form:"first_name"`
+// atau tag "query" untuk `query:"count"`, lalu memvalidasinya dengan tag validate
+// Nilai yang tidak bisa dibaca maupun aturan yang dilanggar menjadi ValidationErrors (422)
+func DecodeValues(values url.Values, tag string, destination interface{}) error {
+	structValue := reflect.ValueOf(destination).Elem()
+	fieldName := valuesFieldName(tag)
+
+	var validation ValidationErrors
+	for index := 0; index < structValue.NumField(); index++ {
+		name, ok := fieldName(structValue.Type().Field(index))
+		if !ok || !values.Has(name) {
+			continue
+		}
+		err := setFieldValue(structValue.Field(index), values.Get(name))
+		if err != nil {
+			validation.Add(name, err.Error())
+		}
+	}
+	if err := validation.Err(); err != nil {
+		return err
+	}
+	return validateStruct(destination, fieldName)
+}
+
+// Mengubah teks menjadi nilai field, field pointer diisi sehingga bisa dibedakan dari yang tidak dikirim
+func setFieldValue(field reflect.Value, text string) error {
+	if field.Kind() == reflect.Pointer {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("harus berupa true atau false")
+		}
+		field.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("harus berupa angka bulat")
+		}
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("harus berupa angka bulat positif")
+		}
+		field.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("harus berupa angka")
+		}
+		field.SetFloat(value)
+	default:
+		return fmt.Errorf("tipe %s tidak didukung", field.Type())
+	}
+	return nil
+}
+
+func checkFieldRules(value reflect.Value, rules fieldRules) []string {
+	// Pointer nil berarti tidak dikirim; pointer yang terisi memenuhi required
+	// (kecuali string kosong), dan aturan lain berlaku untuk nilai yang ditunjuk
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if rules.Required {
+				return []string{"wajib diisi"}
+			}
+			return nil
+		}
+		value = value.Elem()
+		if value.Kind() != reflect.String {
+			rules.Required = false
+		}
+	}
+
+	var size float64
+	unit := ""
+	number := false
+	switch value.Kind() {
+	case reflect.String:
+		size, unit = float64(len([]rune(value.String()))), " karakter"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		size, unit = float64(value.Len()), " item"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size, number = float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size, number = float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		size, number = value.Float(), true
+	}
+
+	// String yang hanya berisi spasi dianggap kosong
+	empty := value.IsZero()
+	if value.Kind() == reflect.String {
+		empty = strings.TrimSpace(value.String()) == ""
+	}
+	if empty {
+		if rules.Required {
+			return []string{"wajib diisi"}
+		}
+		// Field opsional yang kosong dilewati, kecuali angka: min=1 tetap menolak 0
+		if !number {
+			return nil
+		}
+	}
+	if !number && unit == "" {
+		return nil
+	}
+
+	var messages []string
+	if rules.Min != nil && size < *rules.Min {
+		messages = append(messages, fmt.Sprintf("minimal %g%s", *rules.Min, unit))
+	}
+	if rules.Max != nil && size > *rules.Max {
+		messages = append(messages, fmt.Sprintf("maksimal %g%s", *rules.Max, unit))
+	}
+	if value.Kind() == reflect.String {
+		text := value.String()
+		if rules.Format == "email" {
+			if _, err := mail.ParseAddress(text); err != nil {
+				messages = append(messages, "format email tidak valid")
+			}
+		}
+		if len(rules.Enum) > 0 && !containsString(rules.Enum, text) {
+			messages = append(messages, "harus salah satu dari "+strings.Join(rules.Enum, ", "))
+		}
+	}
+	return messages
+}
+
+func TestValidateStruct(t *testing.T) {
+	type Product struct {
+		Name     string   `json:"name" validate:"required,max=20"`
+		Price    int      `json:"price" validate:"min=1"`
+		Category string   `json:"category" validate:"oneof=buku game"`
+		Tags     []string `json:"tags,omitempty" validate:"max=2"`
+		Note     string   `json:"-" validate:"required"`
+	}
+
+	tests := []struct {
+		product Product
+		errors  int
+	}{
+		{Product{Name: "Belajar Golang", Price: 100000, Category: "buku"}, 0},
+		{Product{Price: -1, Category: "film"}, 3},
+		{Product{Name: strings.Repeat("a", 21), Price: 1, Tags: []string{"a", "b", "c"}}, 2},
+		{Product{Name: "   ", Category: "buku"}, 2}, // Nama hanya spasi dan harga 0 (min=1)
+		{Product{Name: "Belajar Golang", Price: 1, Category: "  "}, 0},
+	}
+
+	for _, test := range tests {
+		err := ValidateStruct(test.product)
+		fmt.Println(err)
+
+		validation, _ := err.(ValidationErrors)
+		if len(validation) != test.errors {
+			t.Errorf("%+v: expected %d errors, got %d", test.product, test.errors, len(validation))
+		}
+	}
+}
+
+func TestDecodeValues(t *testing.T) {
+	type Search struct {
+		Keyword string `query:"q" validate:"required,max=10"`
+		Page    *int   `query:"page" validate:"required,min=0"`
+		Exact   bool   `query:"exact"`
+	}
+
+	tests := []struct {
+		query  string
+		errors []string // Nama field yang gagal
+	}{
+		{"q=golang&page=0&exact=true", nil},
+		{"q=golang", []string{"page"}},
+		{"q=%20%20&page=-1", []string{"q", "page"}},
+		{"q=golang&page=satu&exact=mungkin", []string{"page", "exact"}},
+	}
+
+	for _, test := range tests {
+		values, _ := url.ParseQuery(test.query)
+		var search Search
+		err := DecodeValues(values, "query", &search)
+		fmt.Println(test.query, err)
+
+		validation, _ := err.(ValidationErrors)
+		var fields []string
+		for _, fieldError := range validation {
+			fields = append(fields, fieldError.Field)
+		}
+		if fmt.Sprint(fields) != fmt.Sprint(test.errors) {
+			t.Errorf("%s: expected errors on %v, got %v", test.query, test.errors, validation)
+		}
+	}
+
+	// Nilai yang valid tersimpan di struct
+	values, _ := url.ParseQuery("q=golang&page=2&exact=true")
+	var search Search
+	DecodeValues(values, "query", &search)
+	if search.Keyword != "golang" || search.Page == nil || *search.Page != 2 || !search.Exact {
+		t.Errorf("unexpected decoded struct %+v", search)
+	}
+}
+
+// Kesimpulan:
+// Kode ini menambahkan validasi berbasis struct tag validate dengan aturan required, min, max, format=email, dan oneof. Aturan required menganggap string yang hanya berisi spasi sebagai kosong, sedangkan min dan max tetap berlaku untuk angka bernilai nol. Nama field di pesan error mengikuti tag json sehingga sama dengan yang dikirim client, dan hasilnya berupa ValidationErrors yang otomatis menjadi response 422. DecodeValues mengisi struct dari query string atau body form berdasarkan tag query atau form lalu memvalidasinya dengan aturan yang sama, dengan nama field di pesan error mengikuti tag tersebut; field pointer membedakan nilai yang tidak dikirim dari nilai nol. Aturan yang sama dibaca oleh parseValidateTag sehingga generator OpenAPI dapat menuliskannya sebagai batasan schema.
@@ -2,7 +2,6 @@ package belajar_golang_web // Nama package sesuai modul atau folder project
 
 import (
 	"fmt"               // Digunakan untuk mencetak output ke console
-	"html/template"     // Package template HTML Go (aman, auto-escape)
 	"io"                // Digunakan untuk membaca response body
 	"net/http"          // Package HTTP untuk handler
 	"net/http/httptest" // Package untuk testing HTTP handler tanpa server nyata
@@ -10,11 +9,8 @@ import (
 )
 
 func TemplateActionIf(writer http.ResponseWriter, request *http.Request) {
-	// Mem-parsing file template yang berisi action {{if}}
-	t := template.Must(template.ParseFiles("./templates/if.gohtml"))
-
-	// Menjalankan template dengan data berbentuk struct Page
-	t.ExecuteTemplate(writer, "if.gohtml", Page{
+	// Menjalankan template berisi action {{if}} dari template engine dengan data berbentuk struct Page
+	Render(writer, request, myTemplates, "if.gohtml", Page{
 		Title: "Template Action If", // Judul halaman
 		Name:  "Hilmi",              // Nama untuk logika if di template
 	})
@@ -36,11 +32,8 @@ func TestTemplateActionIf(t *testing.T) {
 }
 
 func TemplateActionOperator(writer http.ResponseWriter, request *http.Request) {
-	// Mem-parsing template yang berisi operator perbandingan (eq, gt, lt, dll)
-	t := template.Must(template.ParseFiles("./templates/comparator.gohtml"))
-
-	// Menjalankan template dengan data map
-	t.ExecuteTemplate(writer, "comparator.gohtml", map[string]interface{}{
+	// Menjalankan template berisi operator perbandingan (eq, gt, lt, dll) dengan data map
+	Render(writer, request, myTemplates, "comparator.gohtml", map[string]interface{}{
 		"Title":      "Template Action Operator", // Judul halaman
 		"FinalValue": 80,                          // Nilai untuk dibandingkan di template
 	})
@@ -62,11 +55,8 @@ func TestTemplateActionOperator(t *testing.T) {
 }
 
 func TemplateActionRange(writer http.ResponseWriter, request *http.Request) {
-	// Mem-parsing template yang menggunakan action {{range}}
-	t := template.Must(template.ParseFiles("./templates/range.gohtml"))
-
-	// Menjalankan template dengan data slice
-	t.ExecuteTemplate(writer, "range.gohtml", map[string]interface{}{
+	// Menjalankan template yang menggunakan action {{range}} dengan data slice
+	Render(writer, request, myTemplates, "range.gohtml", map[string]interface{}{
 		"Title": "Template Action Range", // Judul halaman
 		"Hobbies": []string{              // Data list untuk dirender dengan range
 			"Game", "Watch", "Code",
@@ -90,11 +80,8 @@ func TestTemplateActionRange(t *testing.T) {
 }
 
 func TemplateActionWith(writer http.ResponseWriter, request *http.Request) {
-	// Mem-parsing template yang menggunakan action {{with}}
-	t := template.Must(template.ParseFiles("./templates/address.gohtml"))
-
-	// Menjalankan template dengan data map bersarang
-	t.ExecuteTemplate(writer, "address.gohtml", map[string]interface{}{
+	// Menjalankan template yang menggunakan action {{with}} dengan data map bersarang
+	Render(writer, request, myTemplates, "address.gohtml", map[string]interface{}{
 		"Title": "Template Action With", // Judul halaman
 		"Name":  "Hilmi",                // Nama pengguna
 		"Address": map[string]interface{}{ // Data nested untuk konteks with
@@ -3,7 +3,6 @@ package belajar_golang_web
 import (
 	"embed"                  // Package untuk embed file ke dalam binary
 	"fmt"                    // Untuk menampilkan output ke console
-	"html/template"          // Package template HTML bawaan Go
 	"io"                     // Untuk membaca body response
 	"net/http"               // Package HTTP server & client
 	"net/http/httptest"      // Package untuk testing HTTP handler
@@ -14,16 +13,19 @@ import (
 //go:embed templates/*.gohtml
 var templates embed.FS // File system virtual berisi template yang di-embed
 
-// Parsing seluruh template sekali di awal (template caching)
-var myTemplates = template.Must(
-	template.ParseFS(templates, "templates/*.gohtml"), // Membaca template dari embed FS
-)
+// Parsing seluruh template sekali di awal (template caching) lewat TemplateEngine:
+// mode production membaca embed FS lewat overlay (bisa ditimpa $OVERLAY_DIR/templates),
+// mode development (TEMPLATE_DEV=1) membaca ./templates dan parse ulang saat file berubah
+var myTemplates = DefaultTemplateEngine()
 
 // Handler HTTP untuk menggunakan template yang sudah di-cache
 func TemplateCaching(writer http.ResponseWriter, request *http.Request) {
 	// Menjalankan template tanpa parsing ulang (lebih efisien)
-	myTemplates.ExecuteTemplate(
+	// Render menulis ke buffer dulu sehingga error template menjadi response 500
+	Render(
 		writer,
+		request,
+		myTemplates,
 		"simple.gohtml",            // Nama file template yang dieksekusi
 		"Hello Template Caching",   // Data yang dikirim ke template
 	)
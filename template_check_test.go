@@ -0,0 +1,568 @@
+package belajar_golang_web
+
+import (
+	"flag"                // Untuk argumen command -templates
+	"fmt"                 // Untuk format pesan error
+	"html/template"       // Package template HTML bawaan Go
+	"io/fs"               // Untuk membaca seluruh file template
+	"os"                  // Untuk membaca folder template dari disk
+	"path"                // Untuk nama file template
+	"reflect"             // Untuk membaca tipe field dan method view model
+	"sort"                // Untuk mengurutkan hasil pengecekan
+	"strings"             // Untuk manipulasi string
+	"testing"             // Untuk unit testing
+	"testing/fstest"      // Filesystem di memory untuk test
+	"text/template/parse" // Parse tree template Go
+)
+
+// Satu kesalahan yang ditemukan checker, lengkap dengan lokasi di file template
+type TemplateCheckError struct {
+	Template string // Nama template yang sedang dicek
+	Location string // Lokasi di file, misal name.gohtml:9:21
+	Message  string // Penjelasan kesalahan
+}
+
+func (checkError TemplateCheckError) Error() string {
+	return checkError.Location + ": " + checkError.Message
+}
+
+// Checker statis yang mencocokkan template dengan tipe Go view model-nya,
+// sehingga typo seperti {{.Adress.Street}} ketahuan sebelum halaman dibuka
+type TemplateChecker struct {
+	Trees map[string]*parse.Tree // Seluruh template (termasuk {{define}}) berdasarkan nama
+	Funcs template.FuncMap       // Function yang tersedia di template
+}
+
+// Membuat checker dari template yang sudah diparse, misal myTemplates.Templates()
+func NewTemplateChecker(tmpl *template.Template, funcs template.FuncMap) *TemplateChecker {
+	checker := &TemplateChecker{Trees: map[string]*parse.Tree{}, Funcs: funcs}
+	for _, item := range tmpl.Templates() {
+		if item.Tree != nil {
+			checker.Trees[item.Name()] = item.Tree
+		}
+	}
+	return checker
+}
+
+// Membuat checker langsung dari file template
+// Parsing memakai SkipFuncCheck, sehingga function yang tidak terdaftar
+// dilaporkan oleh checker alih-alih menggagalkan parsing
+func NewTemplateCheckerFS(fileSystem fs.FS, pattern string, funcs template.FuncMap) (*TemplateChecker, error) {
+	names, err := fs.Glob(fileSystem, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := &TemplateChecker{Trees: map[string]*parse.Tree{}, Funcs: funcs}
+	for _, name := range names {
+		content, err := fs.ReadFile(fileSystem, name)
+		if err != nil {
+			return nil, err
+		}
+		tree := parse.New(path.Base(name))
+		tree.Mode = parse.SkipFuncCheck
+		_, err = tree.Parse(string(content), "", "", checker.Trees)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return checker, nil
+}
+
+// Function bawaan Go template beserta tipe hasilnya (nil = tidak diketahui)
+var builtinTemplateFuncs = map[string]reflect.Type{
+	"and": nil, "or": nil, "call": nil, "index": nil, "slice": nil,
+	"not": reflect.TypeOf(false), "eq": reflect.TypeOf(false), "ne": reflect.TypeOf(false),
+	"lt": reflect.TypeOf(false), "le": reflect.TypeOf(false), "gt": reflect.TypeOf(false), "ge": reflect.TypeOf(false),
+	"len":   reflect.TypeOf(0),
+	"print": reflect.TypeOf(""), "printf": reflect.TypeOf(""), "println": reflect.TypeOf(""),
+	"html": reflect.TypeOf(""), "js": reflect.TypeOf(""), "urlquery": reflect.TypeOf(""),
+}
+
+// Mengecek satu template terhadap tipe view model, misal Check("name.gohtml", Page{})
+func (checker *TemplateChecker) Check(name string, model interface{}) []TemplateCheckError {
+	check := &templateCheck{checker: checker, visited: map[string]bool{}}
+
+	tree, ok := checker.Trees[name]
+	if !ok {
+		return []TemplateCheckError{{Template: name, Location: name, Message: "template tidak ditemukan"}}
+	}
+
+	dataType := knownType(reflect.TypeOf(model))
+	check.checkTree(name, tree, dataType)
+
+	sort.SliceStable(check.errors, func(i, j int) bool {
+		return check.errors[i].Location < check.errors[j].Location
+	})
+	return check.errors
+}
+
+// State selama satu kali pengecekan
+type templateCheck struct {
+	checker *TemplateChecker
+	errors  []TemplateCheckError
+	visited map[string]bool // Mencegah rekursi tak berujung pada {{template}}
+	name    string
+	tree    *parse.Tree
+}
+
+// Tipe interface{} atau any tidak bisa dicek lebih jauh, dianggap tidak diketahui (nil)
+func knownType(t reflect.Type) reflect.Type {
+	if t == nil || (t.Kind() == reflect.Interface && t.NumMethod() == 0) {
+		return nil
+	}
+	return t
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	return t.String()
+}
+
+func (check *templateCheck) report(node parse.Node, format string, args ...interface{}) {
+	location, _ := check.tree.ErrorContext(node)
+	check.errors = append(check.errors, TemplateCheckError{
+		Template: check.name,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (check *templateCheck) checkTree(name string, tree *parse.Tree, dot reflect.Type) {
+	key := name + "|" + typeName(dot)
+	if check.visited[key] {
+		return
+	}
+	check.visited[key] = true
+
+	previousName, previousTree := check.name, check.tree
+	check.name, check.tree = name, tree
+	defer func() { check.name, check.tree = previousName, previousTree }()
+
+	variables := map[string]reflect.Type{"$": dot}
+	if tree.Root != nil {
+		check.walk(tree.Root, dot, variables)
+	}
+}
+
+// Menyalin variabel saat masuk ke blok baru (if, range, with)
+func copyVariables(variables map[string]reflect.Type) map[string]reflect.Type {
+	copied := make(map[string]reflect.Type, len(variables))
+	for name, variableType := range variables {
+		copied[name] = variableType
+	}
+	return copied
+}
+
+// Menelusuri node parse tree sambil membawa tipe "dot" saat ini
+func (check *templateCheck) walk(node parse.Node, dot reflect.Type, variables map[string]reflect.Type) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			check.walk(child, dot, variables)
+		}
+	case *parse.ActionNode:
+		check.pipe(node.Pipe, dot, variables)
+	case *parse.IfNode:
+		check.pipe(node.Pipe, dot, variables)
+		check.walk(node.List, dot, copyVariables(variables))
+		check.walk(node.ElseList, dot, copyVariables(variables))
+	case *parse.WithNode:
+		inner := copyVariables(variables)
+		withType := check.pipe(node.Pipe, dot, inner)
+		check.walk(node.List, withType, inner)
+		check.walk(node.ElseList, dot, copyVariables(variables))
+	case *parse.RangeNode:
+		inner := copyVariables(variables)
+		rangeType := check.pipe(node.Pipe, dot, inner)
+		keyType, elementType := rangeTypes(rangeType)
+		switch len(node.Pipe.Decl) {
+		case 1:
+			inner[node.Pipe.Decl[0].Ident[0]] = elementType
+		case 2:
+			inner[node.Pipe.Decl[0].Ident[0]] = keyType
+			inner[node.Pipe.Decl[1].Ident[0]] = elementType
+		}
+		check.walk(node.List, elementType, inner)
+		check.walk(node.ElseList, dot, copyVariables(variables))
+	case *parse.TemplateNode:
+		// {{template "name"}} tanpa pipeline menerima data nil
+		var templateType reflect.Type
+		if node.Pipe != nil {
+			templateType = check.pipe(node.Pipe, dot, variables)
+		}
+		tree, ok := check.checker.Trees[node.Name]
+		if !ok {
+			check.report(node, "template %q tidak didefinisikan", node.Name)
+			return
+		}
+		check.checkTree(node.Name, tree, templateType)
+	}
+}
+
+// Tipe key dan elemen dari nilai yang di-range
+func rangeTypes(t reflect.Type) (reflect.Type, reflect.Type) {
+	if t == nil {
+		return nil, nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.TypeOf(0), knownType(t.Elem())
+	case reflect.Map:
+		return knownType(t.Key()), knownType(t.Elem())
+	case reflect.Chan:
+		return knownType(t.Elem()), nil
+	case reflect.Int:
+		return t, t
+	}
+	return nil, nil
+}
+
+// Mengecek pipeline dan mengembalikan tipe hasilnya
+func (check *templateCheck) pipe(pipe *parse.PipeNode, dot reflect.Type, variables map[string]reflect.Type) reflect.Type {
+	if pipe == nil {
+		return nil
+	}
+
+	var result reflect.Type
+	for index, command := range pipe.Cmds {
+		result = check.command(command, dot, variables, index > 0)
+	}
+
+	// Variabel yang dideklarasikan, misal {{$name := .Name}}
+	if len(pipe.Decl) == 1 && !pipe.IsAssign {
+		variables[pipe.Decl[0].Ident[0]] = result
+	}
+	return result
+}
+
+// Mengecek satu command, piped = true jika command menerima hasil command sebelumnya
+func (check *templateCheck) command(command *parse.CommandNode, dot reflect.Type, variables map[string]reflect.Type, piped bool) reflect.Type {
+	first := command.Args[0]
+	arguments := command.Args[1:]
+
+	// Argumen dicek lebih dulu agar field yang salah di argumen juga ketahuan
+	for _, argument := range arguments {
+		check.argument(argument, dot, variables)
+	}
+
+	given := len(arguments)
+	if piped {
+		given++
+	}
+
+	switch node := first.(type) {
+	case *parse.FieldNode:
+		return check.fields(node, dot, node.Ident, given)
+	case *parse.ChainNode:
+		return check.fields(node, check.argument(node.Node, dot, variables), node.Field, given)
+	case *parse.VariableNode:
+		variableType, ok := variables[node.Ident[0]]
+		if !ok {
+			check.report(node, "variabel %s tidak didefinisikan", node.Ident[0])
+			return nil
+		}
+		return check.fields(node, variableType, node.Ident[1:], given)
+	case *parse.IdentifierNode:
+		return check.function(node, given)
+	case *parse.PipeNode:
+		return check.pipe(node, dot, copyVariables(variables))
+	default:
+		return check.argument(first, dot, variables)
+	}
+}
+
+// Mengecek argumen command dan mengembalikan tipenya
+func (check *templateCheck) argument(node parse.Node, dot reflect.Type, variables map[string]reflect.Type) reflect.Type {
+	switch node := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return check.fields(node, dot, node.Ident, 0)
+	case *parse.ChainNode:
+		return check.fields(node, check.argument(node.Node, dot, variables), node.Field, 0)
+	case *parse.VariableNode:
+		variableType, ok := variables[node.Ident[0]]
+		if !ok {
+			check.report(node, "variabel %s tidak didefinisikan", node.Ident[0])
+			return nil
+		}
+		return check.fields(node, variableType, node.Ident[1:], 0)
+	case *parse.PipeNode:
+		return check.pipe(node, dot, copyVariables(variables))
+	case *parse.IdentifierNode:
+		return check.function(node, 0)
+	case *parse.StringNode:
+		return reflect.TypeOf("")
+	case *parse.BoolNode:
+		return reflect.TypeOf(false)
+	case *parse.NumberNode:
+		if node.IsInt {
+			return reflect.TypeOf(0)
+		}
+		return reflect.TypeOf(0.0)
+	}
+	return nil
+}
+
+// Mengecek function template: harus terdaftar dan jumlah argumennya sesuai
+func (check *templateCheck) function(node *parse.IdentifierNode, given int) reflect.Type {
+	// Function escaper yang ditambahkan html/template setelah eksekusi
+	if strings.HasPrefix(node.Ident, "_html_template_") {
+		return reflect.TypeOf("")
+	}
+	if result, ok := builtinTemplateFuncs[node.Ident]; ok {
+		return result
+	}
+
+	function, ok := check.checker.Funcs[node.Ident]
+	if !ok {
+		check.report(node, "function %q tidak didefinisikan", node.Ident)
+		return nil
+	}
+	return check.call(node, node.Ident, reflect.TypeOf(function), 0, given)
+}
+
+// Mengecek jumlah argumen function atau method, offset = 1 untuk receiver method
+func (check *templateCheck) call(node parse.Node, name string, functionType reflect.Type, offset int, given int) reflect.Type {
+	want := functionType.NumIn() - offset
+	if functionType.IsVariadic() {
+		if given < want-1 {
+			check.report(node, "%s membutuhkan minimal %d argumen, diberikan %d", name, want-1, given)
+		}
+	} else if given != want {
+		check.report(node, "%s membutuhkan %d argumen, diberikan %d", name, want, given)
+	}
+
+	switch functionType.NumOut() {
+	case 1:
+		return knownType(functionType.Out(0))
+	case 2:
+		if functionType.Out(1) == reflect.TypeOf((*error)(nil)).Elem() {
+			return knownType(functionType.Out(0))
+		}
+	}
+	check.report(node, "%s harus mengembalikan 1 nilai atau (nilai, error)", name)
+	return nil
+}
+
+// Menelusuri rangkaian field/method, misal .Address.Street
+// Argumen hanya boleh diberikan ke elemen terakhir (method)
+func (check *templateCheck) fields(node parse.Node, current reflect.Type, names []string, given int) reflect.Type {
+	for index, name := range names {
+		if current == nil {
+			return nil
+		}
+		arguments := 0
+		if index == len(names)-1 {
+			arguments = given
+		}
+		current = check.field(node, current, name, arguments)
+	}
+	return current
+}
+
+// Mencari field atau method bernama name pada tipe current
+func (check *templateCheck) field(node parse.Node, current reflect.Type, name string, given int) reflect.Type {
+	// Method bisa dimiliki tipe value maupun pointer
+	pointer := current
+	if current.Kind() != reflect.Pointer && current.Kind() != reflect.Interface {
+		pointer = reflect.PointerTo(current)
+	}
+	if method, ok := pointer.MethodByName(name); ok {
+		offset := 1
+		if current.Kind() == reflect.Interface {
+			offset = 0
+		}
+		return check.call(node, "method "+name, method.Type, offset, given)
+	}
+
+	base := current
+	for base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+
+	switch base.Kind() {
+	case reflect.Struct:
+		field, ok := base.FieldByName(name)
+		if !ok || !field.IsExported() {
+			check.report(node, "field %s tidak ada di tipe %s", name, base)
+			return nil
+		}
+		if given > 0 {
+			check.report(node, "%s adalah field, bukan method, tetapi diberi %d argumen", name, given)
+		}
+		return knownType(field.Type)
+	case reflect.Map:
+		if base.Key().Kind() != reflect.String {
+			check.report(node, "key map %s bukan string", base)
+			return nil
+		}
+		return knownType(base.Elem())
+	case reflect.Interface:
+		return nil
+	}
+
+	check.report(node, "tidak bisa mengambil field %s dari tipe %s", name, base)
+	return nil
+}
+
+// Helper test: mengecek template dan melaporkan setiap kesalahan sebagai t.Error
+func AssertTemplateType(t testing.TB, checker *TemplateChecker, name string, model interface{}) {
+	t.Helper()
+	for _, checkError := range checker.Check(name, model) {
+		t.Error(checkError)
+	}
+}
+
+// Daftar view model untuk setiap file di folder templates
+// Template yang datanya berupa map[string]interface{} tidak bisa dicek lebih jauh
+var templateViewModels = map[string]interface{}{
+	"simple.gohtml":         "",
+	"name.gohtml":           Page{},
+	"if.gohtml":             Page{},
+	"address.gohtml":        map[string]interface{}{},
+	"comparator.gohtml":     map[string]interface{}{},
+	"range.gohtml":          map[string]interface{}{},
+	"post.gohtml":           map[string]interface{}{},
+	"layout.gohtml":         map[string]interface{}{},
+	"header.gohtml":         map[string]interface{}{},
+	"footer.gohtml":         nil,
+	"upload.form.gohtml":    nil,
+	"upload.success.gohtml": map[string]interface{}{},
+	"directory.gohtml":      map[string]interface{}{},
+	"error.gohtml":          map[string]interface{}{},
+	"csp.gohtml":            CSPPage{},
+	"greeting.gohtml":       Greeting{},
+}
+
+func TestTemplateChecker(t *testing.T) {
+	// Template dengan berbagai kesalahan yang biasanya baru ketahuan saat runtime
+	broken := template.Must(template.New("broken.gohtml").Parse(`
+<h1>{{.Name}}</h1>
+<p>{{.Adress.Street}}</p>
+<p>{{.Address.Stret}}</p>
+{{with .Address}}<p>{{.City}}</p>{{end}}
+`))
+	checker := NewTemplateChecker(broken, StandardFuncs())
+	assertCheckErrors(t, checker.Check("broken.gohtml", Page{}),
+		"broken.gohtml:3:12: field Adress tidak ada di tipe belajar_golang_web.Page",
+		"broken.gohtml:4:13: field Stret tidak ada di tipe belajar_golang_web.Address",
+		"broken.gohtml:5:22: field City tidak ada di tipe belajar_golang_web.Address",
+	)
+
+	// Method dengan jumlah argumen yang salah dan function yang tidak terdaftar
+	// Dibaca lewat NewTemplateCheckerFS karena html/template menolak function yang tidak terdaftar saat parsing
+	fileSystem := fstest.MapFS{
+		"method.gohtml": {Data: []byte(`{{.SayHello}} {{.SayHello "Nanook" "Budi"}} {{.SayHello "Nanook" | upper}} {{.Name | shout}}`)},
+	}
+	checker, err := NewTemplateCheckerFS(fileSystem, "*.gohtml", StandardFuncs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCheckErrors(t, checker.Check("method.gohtml", MyPage{}),
+		"method.gohtml:1:2: method SayHello membutuhkan 1 argumen, diberikan 0",
+		"method.gohtml:1:16: method SayHello membutuhkan 1 argumen, diberikan 2",
+		`method.gohtml:1:85: function "shout" tidak didefinisikan`,
+	)
+}
+
+// Mencocokkan kesalahan hasil Check dengan daftar yang diharapkan, urutan tidak diperhatikan
+func assertCheckErrors(t *testing.T, checkErrors []TemplateCheckError, expected ...string) {
+	t.Helper()
+	var actual []string
+	for _, checkError := range checkErrors {
+		fmt.Println(checkError)
+		actual = append(actual, checkError.Error())
+	}
+	sort.Strings(actual)
+	sort.Strings(expected)
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestTemplateCheckerRange(t *testing.T) {
+	type Hobby struct {
+		Name string
+	}
+	type Profile struct {
+		Title   string
+		Hobbies []Hobby
+	}
+
+	tmpl := template.Must(template.New("hobby.gohtml").Funcs(StandardFuncs()).Parse(
+		`{{range $index, $hobby := .Hobbies}}{{$index}} {{$hobby.Name}} {{.Nama}} {{$.Titel}}{{end}}`,
+	))
+	checker := NewTemplateChecker(tmpl, StandardFuncs())
+	// $index dan $hobby.Name valid, sedangkan .Nama (di dalam range) dan $.Titel (root) salah
+	assertCheckErrors(t, checker.Check("hobby.gohtml", Profile{}),
+		"hobby.gohtml:1:65: field Nama tidak ada di tipe belajar_golang_web.Hobby",
+		"hobby.gohtml:1:76: field Titel tidak ada di tipe belajar_golang_web.Profile",
+	)
+}
+
+func TestAssertTemplateType(t *testing.T) {
+	// Dipakai di test lain untuk memastikan template cocok dengan view model-nya
+	templates, err := myTemplates.Templates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := NewTemplateChecker(templates, StandardFuncs())
+	AssertTemplateType(t, checker, "name.gohtml", Page{})
+	AssertTemplateType(t, checker, "if.gohtml", Page{})
+}
+
+// Folder template yang dicek oleh command, kosong berarti template embed (lewat overlay)
+var checkTemplatesDir = flag.String("templates", "", "folder template yang dicek TestCheckTemplatesDirectory")
+
+// Command untuk mengecek seluruh folder templates, exit code bukan 0 jika ada kesalahan:
+//
+//	go test -run '^TestCheckTemplatesDirectory$' .
+//	go test -run '^TestCheckTemplatesDirectory$' . -args -templates ./templates
+//
+// Atau dibuild sekali menjadi binary lalu dijalankan di CI:
+//
+//	go test -c -o checktemplates .
+//	./checktemplates -test.run '^TestCheckTemplatesDirectory$' -templates ./templates
+func TestCheckTemplatesDirectory(t *testing.T) {
+	var fileSystem fs.FS = overlayTemplates
+	if *checkTemplatesDir != "" {
+		fileSystem = os.DirFS(*checkTemplatesDir)
+	}
+
+	checker, err := NewTemplateCheckerFS(fileSystem, "*.gohtml", StandardFuncs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names, _ := fs.Glob(fileSystem, "*.gohtml")
+	if len(names) == 0 {
+		t.Fatal("tidak ada file *.gohtml yang dicek")
+	}
+	for _, name := range names {
+		model, ok := templateViewModels[name]
+		if !ok {
+			fmt.Println("SKIP", name, "(view model belum didaftarkan)")
+			continue
+		}
+		checkErrors := checker.Check(name, model)
+		if len(checkErrors) == 0 {
+			fmt.Println("OK  ", name)
+		}
+		for _, checkError := range checkErrors {
+			t.Error(checkError)
+		}
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat TemplateChecker yang menelusuri parse tree template dan mencocokkannya dengan tipe Go view model seperti Page atau MyPage, sehingga field yang tidak ada (misal .Adress), method dengan jumlah argumen yang salah (misal MyPage.SayHello tanpa argumen), dan function template yang tidak terdaftar dilaporkan beserta lokasi file dan barisnya. Checker dapat dipakai sebagai helper test melalui AssertTemplateType maupun dijalankan sebagai command untuk seluruh folder templates lewat go test -run '^TestCheckTemplatesDirectory$' (folder lain dipilih dengan -args -templates, dan go test -c menghasilkan binary yang bisa dijalankan di CI).
@@ -2,7 +2,6 @@ package belajar_golang_web // Nama package sesuai modul atau folder project
 
 import (
 	"fmt"               // Digunakan untuk menampilkan output ke console
-	"html/template"     // Package untuk HTML template Go yang aman (auto escaping)
 	"io"                // Digunakan untuk membaca response body
 	"net/http"          // Package standar HTTP untuk handler
 	"net/http/httptest" // Package untuk testing HTTP handler tanpa server nyata
@@ -10,11 +9,8 @@ import (
 )
 
 func TemplateDataMap(writer http.ResponseWriter, request *http.Request) {
-	// Mem-parsing file template dari filesystem
-	t := template.Must(template.ParseFiles("./templates/name.gohtml"))
-
-	// Menjalankan template dengan data berbentuk map
-	t.ExecuteTemplate(writer, "name.gohtml", map[string]interface{}{
+	// Menjalankan template dari template engine dengan data berbentuk map
+	Render(writer, request, myTemplates, "name.gohtml", map[string]interface{}{
 		"Title": "Template Data Map", // Data judul halaman
 		"Name":  "Hilmi",             // Data nama yang akan ditampilkan di template
 		"Address": map[string]interface{}{ // Nested map untuk data alamat
@@ -51,11 +47,8 @@ type Page struct {
 }
 
 func TemplateDataStruct(writer http.ResponseWriter, request *http.Request) {
-	// Mem-parsing file template dari filesystem
-	t := template.Must(template.ParseFiles("./templates/name.gohtml"))
-
-	// Menjalankan template dengan data berbentuk struct
-	t.ExecuteTemplate(writer, "name.gohtml", Page{
+	// Menjalankan template dari template engine dengan data berbentuk struct
+	Render(writer, request, myTemplates, "name.gohtml", Page{
 		Title: "Template Data Struct", // Judul halaman
 		Name:  "Hilmi",                // Nama pengguna
 		Address: Address{              // Data alamat dalam struct
@@ -0,0 +1,357 @@
+package belajar_golang_web
+
+import (
+	"fmt"               // Untuk menampilkan output ke console
+	"html/template"     // Package template HTML bawaan Go
+	"io"                // Untuk writer hasil render
+	"io/fs"             // Abstraksi filesystem (embed maupun disk)
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"os"                // Untuk folder template di disk
+	"path/filepath"     // Untuk membuat file template saat test
+	"regexp"            // Untuk mengambil nama file dan nomor baris dari error
+	"strconv"           // Untuk konversi nomor baris
+	"strings"           // Untuk manipulasi string
+	"sync"              // Untuk mengamankan proses reload
+	"sync/atomic"       // Untuk mengganti template secara atomic
+	"testing"           // Untuk unit testing
+	"time"              // Untuk interval polling
+)
+
+// Hasil parsing template, disimpan bersama error agar bisa diganti secara atomic
+type parsedTemplates struct {
+	templates *template.Template
+	err       error
+}
+
+// Template engine tunggal:
+// - mode production: parse sekali dari embed.FS
+// - mode development: cek perubahan file (polling mtime) lalu parse ulang
+type TemplateEngine struct {
+	FileSystem   fs.FS            // Sumber template
+	Pattern      string           // Pola file template, default "*.gohtml"
+	Funcs        template.FuncMap // Function yang didaftarkan ke template
+	Development  bool             // true = hot reload dan error ditampilkan di browser
+	PollInterval time.Duration    // Jeda minimal antar pengecekan perubahan file
+
+	current   atomic.Pointer[parsedTemplates]
+	mutex     sync.Mutex
+	stamps    map[string]time.Time
+	lastCheck time.Time
+}
+
+// Membuat template engine dan langsung mem-parsing template
+// Di mode production error parsing dikembalikan, di mode development disimpan untuk ditampilkan
+func NewTemplateEngine(fileSystem fs.FS, development bool) (*TemplateEngine, error) {
+	engine := &TemplateEngine{
+		FileSystem:   fileSystem,
+		Pattern:      "*.gohtml",
+		Funcs:        StandardFuncs(),
+		Development:  development,
+		PollInterval: time.Second,
+	}
+
+	err := engine.Reload()
+	if err != nil && !development {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// Environment variable untuk menyalakan mode development, misal TEMPLATE_DEV=1
+const templateDevelopmentEnv = "TEMPLATE_DEV"
+
+// Template engine mode development membaca langsung dari folder ./templates
+func DevelopmentTemplateEngine() *TemplateEngine {
+	engine, _ := NewTemplateEngine(os.DirFS("./templates"), true)
+	return engine
+}
+
+// Template engine mode production membaca dari embed (lewat overlay)
+func ProductionTemplateEngine() *TemplateEngine {
+	engine, err := NewTemplateEngine(overlayTemplates, false)
+	if err != nil {
+		panic(err)
+	}
+	return engine
+}
+
+// Template engine yang dipakai seluruh handler (myTemplates):
+// mode development jika TEMPLATE_DEV diisi, selain itu mode production
+func DefaultTemplateEngine() *TemplateEngine {
+	if os.Getenv(templateDevelopmentEnv) != "" {
+		return DevelopmentTemplateEngine()
+	}
+	return ProductionTemplateEngine()
+}
+
+// Mengambil waktu modifikasi seluruh file template
+func (engine *TemplateEngine) modTimes() (map[string]time.Time, error) {
+	names, err := fs.Glob(engine.FileSystem, engine.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	stamps := map[string]time.Time{}
+	for _, name := range names {
+		info, err := fs.Stat(engine.FileSystem, name)
+		if err != nil {
+			return nil, err
+		}
+		stamps[name] = info.ModTime()
+	}
+	return stamps, nil
+}
+
+// Mem-parsing ulang seluruh template, template lama diganti hanya setelah parsing selesai
+func (engine *TemplateEngine) Reload() error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	return engine.reload()
+}
+
+func (engine *TemplateEngine) reload() error {
+	stamps, err := engine.modTimes()
+	if err == nil {
+		engine.stamps = stamps
+	}
+
+	var templates *template.Template
+	if err == nil {
+		templates, err = template.New("").Funcs(engine.Funcs).ParseFS(engine.FileSystem, engine.Pattern)
+	}
+
+	engine.current.Store(&parsedTemplates{templates: templates, err: err})
+	return err
+}
+
+// Mengecek perubahan file di mode development, maksimal sekali per PollInterval
+func (engine *TemplateEngine) checkChanges() {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	if time.Since(engine.lastCheck) < engine.PollInterval {
+		return
+	}
+	engine.lastCheck = time.Now()
+
+	stamps, err := engine.modTimes()
+	if err != nil {
+		engine.reload()
+		return
+	}
+
+	changed := len(stamps) != len(engine.stamps)
+	for name, modTime := range stamps {
+		if !engine.stamps[name].Equal(modTime) {
+			changed = true
+			break
+		}
+	}
+	if changed {
+		fmt.Println("Template berubah, parsing ulang")
+		engine.reload()
+	}
+}
+
+// Mengambil kumpulan template terbaru
+func (engine *TemplateEngine) Templates() (*template.Template, error) {
+	if engine.Development {
+		engine.checkChanges()
+	}
+	parsed := engine.current.Load()
+	return parsed.templates, parsed.err
+}
+
+// Menjalankan template berdasarkan nama
+func (engine *TemplateEngine) ExecuteTemplate(writer io.Writer, name string, data interface{}) error {
+	templates, err := engine.Templates()
+	if err != nil {
+		return err
+	}
+	return templates.ExecuteTemplate(writer, name, data)
+}
+
+// Pola error template Go, misal "template: simple.gohtml:3: unexpected ..."
+var templateErrorPattern = regexp.MustCompile(`template: ([^:\s]+):(\d+):`)
+
+// Informasi error template untuk halaman error di mode development
+type TemplateErrorPage struct {
+	Message string
+	File    string
+	Line    int
+	Source  []TemplateSourceLine
+}
+
+type TemplateSourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// Membuat informasi error lengkap dengan potongan source di sekitar baris error
+func (engine *TemplateEngine) errorPage(err error) TemplateErrorPage {
+	page := TemplateErrorPage{Message: err.Error()}
+
+	match := templateErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return page
+	}
+	page.File = match[1]
+	page.Line, _ = strconv.Atoi(match[2])
+
+	content, readErr := fs.ReadFile(engine.FileSystem, page.File)
+	if readErr != nil {
+		return page
+	}
+	lines := strings.Split(string(content), "\n")
+	for number := max(1, page.Line-3); number <= min(len(lines), page.Line+3); number++ {
+		page.Source = append(page.Source, TemplateSourceLine{
+			Number:  number,
+			Text:    lines[number-1],
+			Current: number == page.Line,
+		})
+	}
+	return page
+}
+
+// Template halaman error mode development
+var templateErrorTemplate = template.Must(template.New("TEMPLATE_ERROR").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>Template Error</title>
+</head>
+<body>
+<h1>Template Error</h1>
+{{if .File}}<h2>{{.File}} baris {{.Line}}</h2>{{end}}
+<pre>{{.Message}}</pre>
+{{if .Source}}<pre>{{range .Source}}{{if .Current}}<mark>{{.Number}}: {{.Text}}</mark>{{else}}{{.Number}}: {{.Text}}{{end}}
+{{end}}</pre>{{end}}
+</body>
+</html>`))
+
+// Menulis halaman error template mode development, dipanggil oleh Render
+func (engine *TemplateEngine) writeErrorPage(writer http.ResponseWriter, err error) {
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.WriteHeader(http.StatusInternalServerError)
+	templateErrorTemplate.Execute(writer, engine.errorPage(err))
+}
+
+// Merender template ke response lewat Render, sehingga error ditangani di satu tempat:
+// di mode development error ditampilkan di browser lengkap dengan file dan baris,
+// di mode production client mendapat halaman 500 dari ErrorPage
+func (engine *TemplateEngine) Render(writer http.ResponseWriter, request *http.Request, name string, data interface{}) {
+	Render(writer, request, engine, name, data)
+}
+
+func TestTemplateEngineProduction(t *testing.T) {
+	engine := ProductionTemplateEngine()
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+	recorder := httptest.NewRecorder()
+	engine.Render(recorder, request, "simple.gohtml", "Hello Template Engine")
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+	if !strings.Contains(string(body), "Hello Template Engine") {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	// Error di mode production melewati ErrorPage yang sama dengan Render
+	recorder = httptest.NewRecorder()
+	engine.Render(recorder, request, "tidak-ada.gohtml", nil)
+
+	response := recorder.Result()
+	body, _ = io.ReadAll(response.Body)
+	fmt.Println(response.StatusCode, response.Header.Get("Content-Type"))
+	if response.StatusCode != http.StatusInternalServerError || response.Header.Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("expected 500 HTML error page, got %d %q", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	if strings.Contains(string(body), "tidak-ada.gohtml") {
+		t.Error("production error page leaks template details")
+	}
+}
+
+func TestTemplateEngineHotReload(t *testing.T) {
+	directory := t.TempDir()
+	file := filepath.Join(directory, "hello.gohtml")
+	os.WriteFile(file, []byte(`<h1>Hello {{.}}</h1>`), 0644)
+
+	engine, err := NewTemplateEngine(os.DirFS(directory), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.PollInterval = 0 // Cek perubahan di setiap request
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+	recorder := httptest.NewRecorder()
+	engine.Render(recorder, request, "hello.gohtml", "Hilmi")
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+
+	// Mengubah isi template, mtime dimajukan agar perubahan terdeteksi
+	os.WriteFile(file, []byte(`<h1>Halo {{.}}, template sudah diubah</h1>`), 0644)
+	os.Chtimes(file, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+
+	recorder = httptest.NewRecorder()
+	engine.Render(recorder, request, "hello.gohtml", "Hilmi")
+	body, _ = io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+	if string(body) != "<h1>Halo Hilmi, template sudah diubah</h1>" {
+		t.Errorf("template not reloaded: %q", body)
+	}
+}
+
+func TestTemplateEngineDevelopmentError(t *testing.T) {
+	directory := t.TempDir()
+	os.WriteFile(filepath.Join(directory, "broken.gohtml"), []byte("<h1>Hello</h1>\n<p>{{.Name</p>\n"), 0644)
+
+	// Di mode development error parsing tidak membuat panic
+	engine, err := NewTemplateEngine(os.DirFS(directory), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+	recorder := httptest.NewRecorder()
+	engine.Render(recorder, request, "broken.gohtml", nil)
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	fmt.Println(response.StatusCode)
+	fmt.Println(string(body))
+	if response.StatusCode != http.StatusInternalServerError || !strings.Contains(string(body), "broken.gohtml baris 2") {
+		t.Errorf("expected development error page with file and line, got %d %q", response.StatusCode, body)
+	}
+
+	// Di mode production error parsing dikembalikan saat engine dibuat
+	_, err = NewTemplateEngine(os.DirFS(directory), false)
+	fmt.Println(err)
+	if err == nil {
+		t.Error("expected parse error in production mode")
+	}
+}
+
+func TestTemplateEngineServer(t *testing.T) {
+	engine := DevelopmentTemplateEngine()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		engine.Render(writer, request, "simple.gohtml", "Hello Template Engine")
+	})
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: mux,
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini menyatukan cara memuat template ke dalam satu TemplateEngine. Di mode production template diparse sekali dari embed.FS, sedangkan di mode development engine mengecek waktu modifikasi file secara polling (tanpa library eksternal) dan mem-parsing ulang lalu mengganti template secara atomic. Error parsing di mode development tidak lagi menyebabkan panic seperti template.Must, melainkan ditampilkan di browser lengkap dengan nama file, nomor baris, dan potongan source template. Engine ini dipakai sebagai myTemplates oleh seluruh handler (mode dipilih lewat TEMPLATE_DEV), dan error render ditangani oleh Render yang sama dengan handler lain.
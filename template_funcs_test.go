@@ -0,0 +1,387 @@
+package belajar_golang_web
+
+import (
+	"encoding/json" // Untuk function json di konteks script
+	"errors"        // Untuk membuat error dari function template
+	"fmt"           // Untuk format angka dan string
+	"html/template" // Package template HTML bawaan Go
+	"math"          // Untuk nilai absolut dan pengecekan pecahan
+	"net/url"       // Untuk membangun URL dan query string yang aman
+	"reflect"       // Untuk mengecek nilai kosong pada function default
+	"strconv"       // Untuk konversi angka
+	"strings"       // Untuk manipulasi string
+	"testing"       // Untuk unit testing
+	"time"          // Untuk format tanggal dan waktu relatif
+	"unicode/utf8"  // Untuk memotong string tanpa merusak karakter
+)
+
+// Waktu sekarang, dibuat variabel agar bisa diganti saat test
+var timeNow = time.Now
+
+// Kumpulan function standar yang didaftarkan ke setiap template set
+// (myTemplates, TemplateEngine, dan ViewEngine)
+func StandardFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Tanggal dan waktu
+		"date":    formatDate,
+		"dateIn":  formatDateIn,
+		"timeAgo": timeAgo,
+
+		// Angka dan ukuran
+		"number": formatNumber,
+		"bytes":  formatFileSize,
+
+		// Teks
+		"upper":     strings.ToUpper,
+		"lower":     strings.ToLower,
+		"pluralize": pluralize,
+		"truncate":  truncate,
+		"default":   defaultValue,
+
+		// Rich text dari user, dibersihkan dulu sebelum menjadi template.HTML
+		"sanitize": sanitizeHTML,
+		"markdown": renderMarkdown,
+
+		// Nonce Content-Security-Policy milik request, {{cspNonce .Request}}
+		"cspNonce": cspNonceFunc,
+
+		// Terjemahan dan format sesuai locale, {{t .Locale "address.none"}}
+		"t":       translate,
+		"tnumber": translateNumber,
+		"tdate":   translateDate,
+		"locale":  localeName,
+
+		// Membuat data untuk {{template}}
+		"dict": dict,
+		"list": list,
+
+		// URL, JSON, dan range
+		"url":   buildURL,
+		"json":  toJSON,
+		"seq":   seq,
+		"until": until,
+	}
+}
+
+// {{date "02 Jan 2006" .CreatedAt}}
+func formatDate(layout string, value time.Time) string {
+	return value.Format(layout)
+}
+
+// {{dateIn "Asia/Jakarta" "02 Jan 2006 15:04 MST" .CreatedAt}}
+func formatDateIn(zone string, layout string, value time.Time) (string, error) {
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return "", err
+	}
+	return value.In(location).Format(layout), nil
+}
+
+// Waktu relatif antara dua waktu, misal "3 minutes ago" atau "in 2 hours"
+func relativeTime(from time.Time, to time.Time) string {
+	duration := to.Sub(from)
+	future := duration < 0
+	if future {
+		duration = -duration
+	}
+
+	units := []struct {
+		name   string
+		length time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+	}
+
+	if duration < 5*time.Second {
+		return "just now"
+	}
+
+	for _, unit := range units {
+		if duration >= unit.length {
+			count := int(duration / unit.length)
+			text := pluralize(count, unit.name, unit.name+"s")
+			if future {
+				return "in " + text
+			}
+			return text + " ago"
+		}
+	}
+	return "just now"
+}
+
+// {{timeAgo .CreatedAt}}
+func timeAgo(value time.Time) string {
+	return relativeTime(value, timeNow())
+}
+
+// Konversi angka ke float64 untuk function yang menerima berbagai tipe angka
+func toFloat(value interface{}) (float64, error) {
+	switch number := value.(type) {
+	case int:
+		return float64(number), nil
+	case int64:
+		return float64(number), nil
+	case int32:
+		return float64(number), nil
+	case uint:
+		return float64(number), nil
+	case uint64:
+		return float64(number), nil
+	case float32:
+		return float64(number), nil
+	case float64:
+		return number, nil
+	case string:
+		return strconv.ParseFloat(number, 64)
+	default:
+		return 0, fmt.Errorf("nilai %v bukan angka", value)
+	}
+}
+
+// {{number 1234567}} -> 1,234,567 dan {{number 1234.5}} -> 1,234.50
+func formatNumber(value interface{}) (string, error) {
+	number, err := toFloat(value)
+	if err != nil {
+		return "", err
+	}
+
+	negative := number < 0
+	number = math.Abs(number)
+
+	// Angka pecahan ditampilkan dengan 2 digit di belakang koma
+	text := strconv.FormatFloat(number, 'f', 0, 64)
+	if number != math.Trunc(number) {
+		text = strconv.FormatFloat(number, 'f', 2, 64)
+	}
+	digits, fraction, _ := strings.Cut(text, ".")
+
+	builder := new(strings.Builder)
+	if negative {
+		builder.WriteString("-")
+	}
+	for index, digit := range digits {
+		if index > 0 && (len(digits)-index)%3 == 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteRune(digit)
+	}
+	if fraction != "" {
+		builder.WriteString("." + fraction)
+	}
+	return builder.String(), nil
+}
+
+// {{pluralize .Count "file" "files"}} -> "1 file" atau "3 files"
+func pluralize(count int, singular string, plural string) string {
+	if count == 1 || count == -1 {
+		return strconv.Itoa(count) + " " + singular
+	}
+	return strconv.Itoa(count) + " " + plural
+}
+
+// {{.Body | truncate 20}}, dipotong per karakter (bukan per byte) lalu ditambah "..."
+func truncate(length int, value string) string {
+	if utf8.RuneCountInString(value) <= length {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:length]) + "..."
+}
+
+// {{.Name | default "Anonim"}}, nilai kosong (zero value) diganti nilai default
+func defaultValue(fallback interface{}, value interface{}) interface{} {
+	if value == nil {
+		return fallback
+	}
+	reflected := reflect.ValueOf(value)
+	if reflected.IsZero() {
+		return fallback
+	}
+	switch reflected.Kind() {
+	case reflect.Slice, reflect.Map:
+		if reflected.Len() == 0 {
+			return fallback
+		}
+	}
+	return value
+}
+
+// {{template "card" dict "Title" .Title "User" .User}}
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict membutuhkan pasangan key dan value")
+	}
+	result := make(map[string]interface{}, len(pairs)/2)
+	for index := 0; index < len(pairs); index += 2 {
+		key, ok := pairs[index].(string)
+		if !ok {
+			return nil, fmt.Errorf("key dict harus string, bukan %T", pairs[index])
+		}
+		result[key] = pairs[index+1]
+	}
+	return result, nil
+}
+
+// {{range list "Game" "Watch" "Code"}}
+func list(items ...interface{}) []interface{} {
+	return items
+}
+
+// {{url "/search" "q" .Query "page" 2}} -> /search?page=2&q=golang+web
+// Nilai query di-encode, sehingga input user tidak bisa menyisipkan parameter lain
+func buildURL(base string, pairs ...interface{}) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", errors.New("url membutuhkan pasangan key dan value")
+	}
+
+	parsed, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsed.Query()
+	for index := 0; index < len(pairs); index += 2 {
+		key := fmt.Sprint(pairs[index])
+		query.Add(key, fmt.Sprint(pairs[index+1]))
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// <script>var data = {{json .}};</script>
+// json.Marshal meng-escape <, > dan & sehingga aman di dalam tag script
+func toJSON(value interface{}) (template.JS, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(encoded), nil
+}
+
+// {{range seq 1 5}} -> 1 2 3 4 5, {{range seq 3}} -> 1 2 3
+func seq(bounds ...int) ([]int, error) {
+	start, end := 1, 0
+	switch len(bounds) {
+	case 1:
+		end = bounds[0]
+	case 2:
+		start, end = bounds[0], bounds[1]
+	default:
+		return nil, errors.New("seq membutuhkan 1 atau 2 argumen")
+	}
+
+	result := []int{}
+	if start <= end {
+		for number := start; number <= end; number++ {
+			result = append(result, number)
+		}
+	} else {
+		for number := start; number >= end; number-- {
+			result = append(result, number)
+		}
+	}
+	return result, nil
+}
+
+// {{range until 3}} -> 0 1 2
+func until(count int) []int {
+	result := make([]int, 0, max(count, 0))
+	for number := 0; number < count; number++ {
+		result = append(result, number)
+	}
+	return result
+}
+
+// Membantu test: merender template string dengan StandardFuncs
+func renderWithStandardFuncs(text string, data interface{}) (string, error) {
+	tmpl, err := template.New("FUNCS").Funcs(StandardFuncs()).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	builder := new(strings.Builder)
+	err = tmpl.Execute(builder, data)
+	return builder.String(), err
+}
+
+func TestStandardFuncs(t *testing.T) {
+	// Waktu sekarang dibuat tetap agar hasil timeAgo bisa diprediksi
+	now := time.Date(2025, 8, 17, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	tests := []struct {
+		name     string
+		text     string
+		data     interface{}
+		expected string
+	}{
+		// Tanggal dan waktu
+		{"date", `{{date "02 Jan 2006" .}}`, now, "17 Aug 2025"},
+		{"dateIn", `{{dateIn "Asia/Jakarta" "15:04 MST" .}}`, now, "17:00 WIB"},
+		{"timeAgo", `{{timeAgo .}}`, now.Add(-3 * time.Minute), "3 minutes ago"},
+		{"timeAgo future", `{{timeAgo .}}`, now.Add(2 * time.Hour), "in 2 hours"},
+		{"timeAgo singular", `{{timeAgo .}}`, now.Add(-24 * time.Hour), "1 day ago"},
+
+		// Angka
+		{"number", `{{number .}}`, 1234567, "1,234,567"},
+		{"number float", `{{number .}}`, -1234.5, "-1,234.50"},
+		{"number rounding", `{{number .}}`, 999.999, "1,000.00"},
+		{"bytes", `{{bytes .}}`, int64(257789), "251.7 KB"},
+
+		// Teks, hasil tetap di-escape di konteks HTML
+		{"pluralize", `{{pluralize . "file" "files"}}`, 3, "3 files"},
+		{"truncate", `{{. | truncate 5}}`, "Hilmi Yahya", "Hilmi..."},
+		{"truncate escaped", `<p>{{. | truncate 8}}</p>`, "<script>alert(1)</script>", "<p>&lt;script&gt;...</p>"},
+		{"default", `{{.Name | default "Anonim"}}`, map[string]interface{}{"Name": ""}, "Anonim"},
+		{"default filled", `{{.Name | default "Anonim"}}`, map[string]interface{}{"Name": "Hilmi"}, "Hilmi"},
+		{"upper escaped", `{{upper .}}`, "<b>", "&lt;B&gt;"},
+
+		// dict dan list untuk {{template}}
+		{"dict", `{{define "card"}}{{.Title}}-{{.Name}}{{end}}{{template "card" dict "Title" "Hi" "Name" .}}`, "<Hilmi>", "Hi-&lt;Hilmi&gt;"},
+		{"list", `{{range list "Game" "Code"}}[{{.}}]{{end}}`, nil, "[Game][Code]"},
+
+		// URL di konteks atribut href, query di-encode
+		{"url attribute", `<a href="{{url "/search" "q" .}}">cari</a>`, `golang&page=2`, `<a href="/search?q=golang%26page%3D2">cari</a>`},
+		{"url javascript blocked", `<a href="{{url .}}">x</a>`, "javascript:alert(1)", `<a href="#ZgotmplZ">x</a>`},
+
+		// JSON di konteks script, karakter HTML di-escape
+		{"json script", `<script>var data = {{json .}};</script>`, map[string]string{"name": "</script><script>alert(1)"}, `<script>var data = {"name":"\u003c/script\u003e\u003cscript\u003ealert(1)"};</script>`},
+
+		// seq dan until
+		{"seq", `{{range seq 3}}{{.}}{{end}}`, nil, "123"},
+		{"seq range", `{{range seq 5 3}}{{.}}{{end}}`, nil, "543"},
+		{"until", `{{range until 3}}{{.}}{{end}}`, nil, "012"},
+	}
+
+	for _, test := range tests {
+		result, err := renderWithStandardFuncs(test.text, test.data)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		fmt.Println(test.name, ":", result)
+		if result != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestStandardFuncsErrors(t *testing.T) {
+	// Argumen yang salah menghasilkan error eksekusi, bukan panic
+	for _, text := range []string{`{{dict "a"}}`, `{{dict 1 2}}`, `{{url "/a" "q"}}`, `{{dateIn "Mars/Base" "15:04" .}}`} {
+		_, err := renderWithStandardFuncs(text, time.Now())
+		fmt.Println(text, ":", err)
+		if err == nil {
+			t.Errorf("%s: expected error", text)
+		}
+	}
+}
+
+// Kesimpulan:
+// Kode ini menyediakan kumpulan function standar untuk template yang didaftarkan ke setiap template set, mulai dari format tanggal dengan zona waktu, waktu relatif, format angka dan ukuran byte, pluralisasi, pemotongan teks, nilai default, dict dan list untuk mengirim banyak nilai ke {{template}}, pembuatan URL yang aman, JSON untuk konteks script, hingga seq dan until untuk perulangan. Setiap function diuji pada berbagai konteks escaping html/template untuk memastikan hasilnya tetap aman.
@@ -2,7 +2,6 @@ package belajar_golang_web
 
 import (
 	"fmt"
-	"html/template"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -10,12 +9,8 @@ import (
 )
 
 func TemplateLayout(writer http.ResponseWriter, request *http.Request) {
-	t := template.Must(template.ParseFiles(
-		"./templates/header.gohtml", 
-		"./templates/footer.gohtml",
-		"./templates/layout.gohtml", 
-	))
-	t.ExecuteTemplate(writer, "layout", map[string]interface{}{
+	// header, footer, dan layout sudah diparse oleh template engine (myTemplates)
+	Render(writer, request, myTemplates, "layout", map[string]interface{}{
 		"Title": "Template Layout",
 		"Name": "Hilmi",
 	})
@@ -65,7 +65,7 @@ func TestSimpleHTMLFile(t *testing.T) {
 
 func TemplateDirectory(writer http.ResponseWriter, request *http.Request) {
 	// Mem-parsing semua file template dengan ekstensi .gohtml dalam satu folder
-	t := template.Must(template.ParseGlob("./templates/*.gohtml"))
+	t := template.Must(template.New("").Funcs(StandardFuncs()).ParseGlob("./templates/*.gohtml"))
 
 	// Menjalankan salah satu template dari kumpulan template
 	t.ExecuteTemplate(writer, "simple.gohtml", "Hello HTML Template")
@@ -90,7 +90,7 @@ func TestTemplateDirectory(t *testing.T) {
 
 func TemplateEmbed(writer http.ResponseWriter, request *http.Request) {
 	// Mem-parsing template langsung dari embedded filesystem
-	t := template.Must(template.ParseFS(templates, "templates/*.gohtml"))
+	t := template.Must(template.New("").Funcs(StandardFuncs()).ParseFS(templates, "templates/*.gohtml"))
 
 	// Menjalankan template dari hasil embed
 	t.ExecuteTemplate(writer, "simple.gohtml", "Hello HTML Template")
@@ -9,45 +9,60 @@ import (
 	"net/http"               // Package utama HTTP server & client
 	"net/http/httptest"      // Untuk testing HTTP tanpa server sungguhan
 	"os"                     // Untuk operasi file system
+	"path/filepath"          // Untuk menyusun path file tujuan upload
 	"testing"                // Untuk unit testing
 )
 
+// Folder tujuan upload, file hasil upload dilayani lewat /static/
+// Test mengganti folder ini dengan t.TempDir() agar folder resources tidak berubah
+var uploadDirectory = downloadDirectory
+
 // Handler untuk menampilkan form upload
 func UploadForm(writer http.ResponseWriter, request *http.Request) {
 	// Render template form upload
-	myTemplates.ExecuteTemplate(writer, "upload.form.gohtml", nil)
+	Render(writer, request, myTemplates, "upload.form.gohtml", nil)
 }
 
 // Handler untuk menerima dan memproses file upload
-func Upload(writer http.ResponseWriter, request *http.Request) {
+// Mengembalikan error (AppHandler) sehingga didaftarkan dengan mux.Handle("/upload", Handle(Upload))
+func Upload(writer http.ResponseWriter, request *http.Request) error {
 	// Mengambil file dari form dengan name="file"
 	file, fileHeader, err := request.FormFile("file")
 	if err != nil {
-		panic(err) // Panic jika file tidak ditemukan / error parsing
+		return NewProblem(http.StatusBadRequest, "field file wajib berisi file") // 400 jika file tidak ada
 	}
 	defer file.Close() // Menutup file setelah selesai digunakan
 
-	// Membuat file baru di folder resources sesuai nama file upload
-	fileDestination, err := os.Create("./resources/" + fileHeader.Filename)
+	// Nama file harus tetap berada di dalam folder upload
+	if !filepath.IsLocal(fileHeader.Filename) {
+		var validation ValidationErrors
+		validation.Add("file", "nama file tidak valid")
+		return validation // 422 beserta error per field
+	}
+	path := filepath.Join(uploadDirectory, fileHeader.Filename)
+
+	// Membuat file baru di folder upload sesuai nama file upload
+	fileDestination, err := os.Create(path)
 	if err != nil {
-		panic(err) // Panic jika gagal membuat file
+		return fmt.Errorf("membuat file %s: %w", path, err) // 500, dicatat ke log
 	}
 	defer fileDestination.Close() // Menutup file tujuan setelah selesai
 
 	// Menyalin isi file upload ke file tujuan
 	_, err = io.Copy(fileDestination, file)
 	if err != nil {
-		panic(err) // Panic jika gagal copy file
+		return fmt.Errorf("menyimpan file %s: %w", path, err)
 	}
 
 	// Mengambil data text dari input name
 	name := request.PostFormValue("name")
 
 	// Menampilkan halaman sukses upload
-	myTemplates.ExecuteTemplate(writer, "upload.success.gohtml", map[string]interface{}{
+	Render(writer, request, myTemplates, "upload.success.gohtml", map[string]interface{}{
 		"Name": name,                                // Nama yang diinput user
 		"File": "/static/" + fileHeader.Filename,   // URL file untuk diakses via browser
 	})
+	return nil
 }
 
 // Test manual menggunakan server sungguhan
@@ -58,7 +73,7 @@ func TestUpload(t *testing.T) {
 	mux.HandleFunc("/", UploadForm)
 
 	// Route upload file
-	mux.HandleFunc("/upload", Upload)
+	mux.Handle("/upload", Handle(Upload))
 
 	// Static file server untuk mengakses file hasil upload
 	mux.Handle("/static/", http.StripPrefix(
@@ -113,8 +128,8 @@ func TestUploadFile(t *testing.T) {
 	// Recorder untuk menangkap response
 	recorder := httptest.NewRecorder()
 
-	// Memanggil handler Upload langsung
-	Upload(recorder, request)
+	// Memanggil handler Upload lewat adapter AppHandler
+	Handle(Upload).ServeHTTP(recorder, request)
 
 	// Membaca response body
 	bodyResponse, _ := io.ReadAll(recorder.Result().Body)
@@ -0,0 +1,529 @@
+package belajar_golang_web
+
+import (
+	"bufio"             // Untuk membaca stream SSE di test
+	"bytes"             // Untuk membuat body multipart di test
+	"encoding/json"     // Untuk data progress
+	"errors"            // Untuk membedakan io.EOF dari error lain
+	"fmt"               // Untuk output ke console
+	"io"                // Untuk membungkus body request
+	"mime/multipart"    // Untuk membuat form upload di test
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk server test di port acak
+	"regexp"            // Untuk validasi upload ID
+	"strings"           // Untuk memeriksa event SSE
+	"sync"              // Untuk mengamankan daftar upload
+	"testing"           // Untuk unit testing
+	"time"              // Untuk retensi progress dan heartbeat
+)
+
+// Upload ID dibuat oleh browser, dibatasi agar aman dipakai sebagai path dan nama topic
+var uploadIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Progress satu upload, dikirim apa adanya sebagai JSON
+type UploadProgress struct {
+	ID       string `json:"id"`
+	Received int64  `json:"received"`        // Byte body request yang sudah diterima
+	Total    int64  `json:"total"`           // Content-Length, -1 jika tidak diketahui
+	Done     bool   `json:"done"`            // Handler upload sudah selesai
+	Error    string `json:"error,omitempty"` // Terisi jika body gagal dibaca, misalnya client membatalkan upload
+}
+
+type uploadState struct {
+	progress  UploadProgress
+	published int64 // Received saat event terakhir dikirim ke Broker
+}
+
+// Mencatat byte yang diterima per upload ID dan mengumumkannya lewat Broker
+type UploadTracker struct {
+	Broker     *Broker       // Event "progress" dikirim ke topic "upload:<id>"
+	Step       int64         // Selisih byte minimal antar event, minimal 1% dari Total
+	Retention  time.Duration // Lama progress disimpan setelah upload selesai
+	MaxUploads int           // Batas jumlah upload yang disimpan, ID dipilih client sehingga harus dibatasi
+
+	mutex   sync.Mutex
+	uploads map[string]*uploadState
+}
+
+func NewUploadTracker() *UploadTracker {
+	return &UploadTracker{
+		Broker:     NewBroker(),
+		Step:       64 << 10,
+		Retention:  time.Minute,
+		MaxUploads: 1000,
+		uploads:    map[string]*uploadState{},
+	}
+}
+
+func uploadTopic(id string) string {
+	return "upload:" + id
+}
+
+// Snapshot progress upload, false jika ID tidak dikenal atau sudah kedaluwarsa
+func (tracker *UploadTracker) Progress(id string) (UploadProgress, bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	state, ok := tracker.uploads[id]
+	if !ok {
+		return UploadProgress{}, false
+	}
+	return state.progress, true
+}
+
+// Harus dipanggil saat mutex sudah dikunci
+// Memberi tempat untuk upload baru dengan membuang satu upload yang sudah selesai lebih awal
+func (tracker *UploadTracker) reserve(id string) bool {
+	if _, ok := tracker.uploads[id]; ok || len(tracker.uploads) < tracker.MaxUploads {
+		return true
+	}
+	for other, state := range tracker.uploads {
+		if state.progress.Done {
+			delete(tracker.uploads, other)
+			return true
+		}
+	}
+	return false
+}
+
+func (tracker *UploadTracker) start(id string, total int64) (*uploadState, error) {
+	tracker.mutex.Lock()
+	if state, ok := tracker.uploads[id]; ok && !state.progress.Done {
+		tracker.mutex.Unlock()
+		return nil, NewProblem(http.StatusConflict, "upload dengan ID ini sedang berjalan")
+	}
+	if !tracker.reserve(id) {
+		tracker.mutex.Unlock()
+		return nil, NewProblem(http.StatusServiceUnavailable, "terlalu banyak upload yang sedang berjalan")
+	}
+	progress := UploadProgress{ID: id, Total: total}
+	state := &uploadState{progress: progress}
+	tracker.uploads[id] = state
+	tracker.mutex.Unlock()
+
+	tracker.Broker.PublishJSON(uploadTopic(id), "progress", progress)
+	return state, nil
+}
+
+// Harus dipanggil saat mutex sudah dikunci
+// Upload yang sudah selesai, kedaluwarsa, atau digantikan upload baru tidak diubah lagi
+func (tracker *UploadTracker) active(state *uploadState) bool {
+	return !state.progress.Done && tracker.uploads[state.progress.ID] == state
+}
+
+func (tracker *UploadTracker) add(state *uploadState, n int64) {
+	tracker.mutex.Lock()
+	if !tracker.active(state) {
+		tracker.mutex.Unlock()
+		return
+	}
+	state.progress.Received += n
+
+	step := max(tracker.Step, state.progress.Total/100)
+	if state.progress.Received-state.published < step {
+		tracker.mutex.Unlock()
+		return
+	}
+	state.published = state.progress.Received
+	progress := state.progress
+	tracker.mutex.Unlock()
+
+	tracker.Broker.PublishJSON(uploadTopic(progress.ID), "progress", progress)
+}
+
+func (tracker *UploadTracker) finish(state *uploadState, err error) {
+	tracker.mutex.Lock()
+	if !tracker.active(state) {
+		tracker.mutex.Unlock()
+		return
+	}
+	state.progress.Done = true
+	if err != nil {
+		state.progress.Error = err.Error()
+	} else if state.progress.Total >= 0 && state.progress.Received < state.progress.Total {
+		// Handler berhenti sebelum seluruh body dibaca, misalnya form rusak atau file tidak dikirim
+		state.progress.Error = "upload berhenti sebelum seluruh body diterima"
+	}
+	state.published = state.progress.Received
+	progress := state.progress
+	tracker.mutex.Unlock()
+
+	id := progress.ID
+	tracker.Broker.PublishJSON(uploadTopic(id), "progress", progress)
+
+	// Progress dihapus setelah Retention, kecuali ID sudah dipakai upload baru
+	time.AfterFunc(tracker.Retention, func() {
+		tracker.mutex.Lock()
+		defer tracker.mutex.Unlock()
+		if tracker.uploads[id] == state {
+			delete(tracker.uploads, id)
+		}
+	})
+}
+
+// Body request yang melaporkan setiap pembacaan
+type countingReader struct {
+	io.ReadCloser
+	onRead func(n int, err error)
+}
+
+func (reader *countingReader) Read(data []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(data)
+	reader.onRead(n, err)
+	return n, err
+}
+
+// Middleware yang menghitung byte stream multipart untuk request dengan ?upload_id=
+// Request tanpa upload_id diteruskan apa adanya, sehingga form tetap bisa dipakai tanpa JavaScript
+func (tracker *UploadTracker) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		id := request.URL.Query().Get("upload_id")
+		if id == "" {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		if !uploadIDPattern.MatchString(id) {
+			var validation ValidationErrors
+			validation.Add("upload_id", "hanya boleh huruf, angka, - dan _ (maksimal 64 karakter)")
+			WriteProblem(writer, request, validation)
+			return
+		}
+
+		state, err := tracker.start(id, request.ContentLength)
+		if err != nil {
+			WriteProblem(writer, request, err)
+			return
+		}
+
+		var readErr error
+		request.Body = &countingReader{ReadCloser: request.Body, onRead: func(n int, err error) {
+			tracker.add(state, int64(n))
+			if err != nil && !errors.Is(err, io.EOF) {
+				readErr = err
+			}
+		}}
+		tracked := &trackingResponseWriter{ResponseWriter: writer}
+		next.ServeHTTP(tracked, request)
+		if readErr == nil && tracked.status >= http.StatusBadRequest {
+			readErr = fmt.Errorf("upload ditolak dengan status %d", tracked.status)
+		}
+		tracker.finish(state, readErr)
+	})
+}
+
+// GET /upload/progress/{id} untuk polling
+func (tracker *UploadTracker) ProgressJSON(writer http.ResponseWriter, request *http.Request) error {
+	progress, ok := tracker.Progress(request.PathValue("id"))
+	if !ok {
+		return NewProblem(http.StatusNotFound, "upload tidak ditemukan")
+	}
+	writer.Header().Set("Cache-Control", "no-store")
+	WriteJSON(writer, request, http.StatusOK, progress)
+	return nil
+}
+
+// GET /upload/progress/{id}/events sebagai Server-Sent Events
+// Event dari Broker hanya dipakai sebagai pemberitahuan; yang dikirim selalu snapshot terbaru,
+// sehingga progress tidak pernah mundur dan stream selesai begitu upload selesai
+func (tracker *UploadTracker) ProgressEvents(writer http.ResponseWriter, request *http.Request) error {
+	id := request.PathValue("id")
+
+	// Subscribe sebelum snapshot diambil agar tidak ada perubahan yang terlewat
+	_, events, cancel := tracker.Broker.Subscribe("", uploadTopic(id))
+	defer cancel()
+
+	progress, ok := tracker.Progress(id)
+	if !ok {
+		return NewProblem(http.StatusNotFound, "upload tidak ditemukan")
+	}
+
+	controller := http.NewResponseController(writer)
+	controller.SetWriteDeadline(time.Time{})
+
+	header := writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(tracker.Broker.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		data, _ := json.Marshal(progress)
+		_, err := Event{Name: "progress", Data: string(data)}.WriteTo(writer)
+		if err == nil {
+			err = controller.Flush()
+		}
+		if err != nil || progress.Done {
+			return err
+		}
+
+		for changed := false; !changed; {
+			select {
+			case <-request.Context().Done():
+				return nil
+			case _, open := <-events:
+				if !open {
+					// Subscriber terlalu lambat diputus Broker, browser akan reconnect dan menerima snapshot
+					return nil
+				}
+				changed = true
+			case <-heartbeat.C:
+				_, err = io.WriteString(writer, ": heartbeat\n\n")
+				if err == nil {
+					err = controller.Flush()
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		progress, ok = tracker.Progress(id)
+		if !ok {
+			return nil
+		}
+	}
+}
+
+// Mendaftarkan endpoint progress ke mux
+func (tracker *UploadTracker) Register(mux *http.ServeMux) {
+	mux.Handle("GET /upload/progress/{id}", Handle(tracker.ProgressJSON))
+	mux.Handle("GET /upload/progress/{id}/events", Handle(tracker.ProgressEvents))
+}
+
+func uploadProgressMux(tracker *UploadTracker) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", UploadForm)
+	mux.Handle("/upload", tracker.Track(Handle(Upload)))
+	tracker.Register(mux)
+	mux.Handle("/static/", http.StripPrefix("/static", http.FileServer(http.Dir("./resources"))))
+	return mux
+}
+
+// Body multipart berisi gambar contoh, sama seperti TestUploadFile
+func uploadProgressBody() ([]byte, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	writer.WriteField("name", "Hilmi Yahya")
+	file, _ := writer.CreateFormFile("file", "contoh-upload.png")
+	file.Write(uploadFileTest)
+	writer.Close()
+	return body.Bytes(), writer.FormDataContentType()
+}
+
+func getUploadProgress(server *httptest.Server, id string) (UploadProgress, int) {
+	var envelope struct {
+		Data UploadProgress `json:"data"`
+	}
+	response, err := http.Get(server.URL + "/upload/progress/" + id)
+	if err != nil {
+		return envelope.Data, 0
+	}
+	defer response.Body.Close()
+	json.NewDecoder(response.Body).Decode(&envelope)
+	return envelope.Data, response.StatusCode
+}
+
+func TestUploadProgress(t *testing.T) {
+	// File hasil upload ditulis ke folder sementara, bukan ke ./resources
+	previous := uploadDirectory
+	uploadDirectory = t.TempDir()
+	t.Cleanup(func() { uploadDirectory = previous })
+
+	tracker := NewUploadTracker()
+	tracker.Step = 16 << 10
+	server := httptest.NewServer(uploadProgressMux(tracker))
+	defer server.Close()
+
+	body, contentType := uploadProgressBody()
+	half := len(body) / 2
+
+	// Body dikirim lewat pipe agar upload bisa ditahan di tengah jalan
+	pipeReader, pipeWriter := io.Pipe()
+	request, _ := http.NewRequest(http.MethodPost, server.URL+"/upload?upload_id=test-1", pipeReader)
+	request.Header.Set("Content-Type", contentType)
+	request.ContentLength = int64(len(body))
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Error(err)
+		}
+		responses <- response
+	}()
+
+	pipeWriter.Write(body[:half])
+	var progress UploadProgress
+	if !waitFor(func() bool {
+		progress, _ = getUploadProgress(server, "test-1")
+		return progress.Received >= int64(half)
+	}) {
+		t.Fatalf("progress not updated: %+v", progress)
+	}
+	fmt.Printf("polling: %+v\n", progress)
+	if progress.Done || progress.Total != int64(len(body)) {
+		t.Errorf("unexpected progress in the middle of upload: %+v", progress)
+	}
+
+	// Stream SSE dimulai dari snapshot terbaru lalu selesai bersama upload
+	stream, err := http.Get(server.URL + "/upload/progress/test-1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	reader := bufio.NewReader(stream.Body)
+	first, _ := readEventBlock(reader)
+	fmt.Print(first)
+
+	pipeWriter.Write(body[half:])
+	pipeWriter.Close()
+
+	last := first
+	for {
+		block, err := readEventBlock(reader)
+		if block != "" {
+			last = block
+		}
+		if err != nil {
+			break
+		}
+	}
+	fmt.Print(last)
+	if !strings.Contains(last, `"done":true`) {
+		t.Errorf("SSE stream ended without done event: %q", last)
+	}
+
+	response := <-responses
+	fmt.Println("upload:", response.StatusCode)
+	response.Body.Close()
+
+	progress, _ = getUploadProgress(server, "test-1")
+	fmt.Printf("selesai: %+v\n", progress)
+	if !progress.Done || progress.Received != int64(len(body)) || progress.Error != "" {
+		t.Errorf("unexpected final progress: %+v", progress)
+	}
+}
+
+func TestUploadProgressErrors(t *testing.T) {
+	tracker := NewUploadTracker()
+	mux := uploadProgressMux(tracker)
+
+	tests := []struct {
+		method string
+		target string
+		status int
+	}{
+		{http.MethodGet, "/upload/progress/tidak-ada", http.StatusNotFound},
+		{http.MethodGet, "/upload/progress/tidak-ada/events", http.StatusNotFound},
+		{http.MethodPost, "/upload?upload_id=../rahasia", http.StatusUnprocessableEntity},
+		{http.MethodPost, "/upload?upload_id=kosong", http.StatusBadRequest},
+		{http.MethodGet, "/upload/progress/kosong", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, "http://localhost:8080"+test.target, nil)
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+
+		response := recorder.Result()
+		body, _ := io.ReadAll(response.Body)
+		fmt.Println(test.method, test.target, response.StatusCode, strings.TrimSpace(string(body)))
+		if response.StatusCode != test.status {
+			t.Errorf("%s %s: expected %d, got %d", test.method, test.target, test.status, response.StatusCode)
+		}
+	}
+
+	// Upload yang ditolak handler tetap selesai, tetapi dengan keterangan error
+	progress, _ := tracker.Progress("kosong")
+	fmt.Printf("%+v\n", progress)
+	if !progress.Done || progress.Error == "" {
+		t.Errorf("expected failed upload to report an error: %+v", progress)
+	}
+}
+
+func TestUploadTrackerIncomplete(t *testing.T) {
+	tracker := NewUploadTracker()
+
+	// Handler yang hanya membaca sebagian body lalu membalas 200
+	handler := tracker.Track(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		io.ReadFull(request.Body, make([]byte, 4))
+		fmt.Fprint(writer, "OK")
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:8080/upload?upload_id=sebagian", strings.NewReader("0123456789"))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	progress, _ := tracker.Progress("sebagian")
+	fmt.Printf("%+v\n", progress)
+	if !progress.Done || progress.Received != 4 || progress.Total != 10 || progress.Error == "" {
+		t.Errorf("expected incomplete upload to report an error: %+v", progress)
+	}
+}
+
+func TestUploadTrackerFinished(t *testing.T) {
+	tracker := NewUploadTracker()
+	state, _ := tracker.start("selesai", 10)
+	tracker.add(state, 4)
+	tracker.finish(state, nil)
+
+	// Sisa body yang dibaca setelah handler selesai tidak lagi dihitung
+	tracker.add(state, 6)
+	progress, _ := tracker.Progress("selesai")
+	fmt.Printf("%+v\n", progress)
+	if progress.Received != 4 || !progress.Done {
+		t.Errorf("progress changed after finish: %+v", progress)
+	}
+
+	// Entry yang sudah kedaluwarsa diabaikan, bukan panic
+	tracker.mutex.Lock()
+	delete(tracker.uploads, "selesai")
+	tracker.mutex.Unlock()
+	tracker.add(state, 1)
+	tracker.finish(state, nil)
+	if _, ok := tracker.Progress("selesai"); ok {
+		t.Error("expired upload came back")
+	}
+}
+
+func TestUploadTrackerLimit(t *testing.T) {
+	tracker := NewUploadTracker()
+	tracker.MaxUploads = 2
+
+	first, _ := tracker.start("satu", 10)
+	tracker.start("dua", 10)
+	_, err := tracker.start("tiga", 10)
+	fmt.Println(err)
+	var problem *Problem
+	if !errors.As(err, &problem) || problem.Status != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while all uploads are running, got %v", err)
+	}
+
+	// Upload yang sudah selesai dibuang lebih awal untuk memberi tempat
+	tracker.finish(first, nil)
+	_, err = tracker.start("tiga", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tracker.Progress("satu"); ok || len(tracker.uploads) != 2 {
+		t.Errorf("expected finished upload to be evicted, have %d uploads", len(tracker.uploads))
+	}
+}
+
+func TestUploadProgressServer(t *testing.T) {
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: uploadProgressMux(NewUploadTracker()),
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini menambahkan pelacakan progress upload. Middleware Track membungkus body request dengan countingReader sehingga setiap byte stream multipart yang dibaca handler Upload tercatat per upload ID, tanpa mengubah handler Upload itu sendiri. Progress bisa dibaca dengan polling ke endpoint JSON atau diikuti lewat Server-Sent Events; event dikirim lewat Broker setiap kelipatan Step atau 1% dari ukuran body, dan stream SSE selalu mengirim snapshot terbaru lalu berakhir ketika upload selesai. Progress disimpan sebentar setelah upload selesai agar browser yang terlambat masih bisa membaca hasil akhirnya. Byte yang dibaca setelah upload selesai atau kedaluwarsa tidak lagi dihitung, upload yang berhenti sebelum seluruh body diterima atau dibalas dengan status 400 ke atas tetap ditandai selesai dengan keterangan Error, dan jumlah upload yang disimpan dibatasi MaxUploads: upload yang sudah selesai dibuang lebih awal, sedangkan jika semuanya masih berjalan upload baru ditolak dengan 503.
@@ -0,0 +1,339 @@
+package belajar_golang_web
+
+import (
+	"embed"             // Untuk embed folder layouts dan pages
+	"fmt"               // Untuk menampilkan output ke console
+	"html/template"     // Package template HTML bawaan Go
+	"io"                // Untuk membaca body response
+	"io/fs"             // Abstraksi filesystem (embed maupun disk)
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk testing HTTP tanpa server sungguhan
+	"os"                // Untuk folder template di disk (mode development)
+	"path"              // Untuk memecah nama file dan folder
+	"path/filepath"     // Untuk mengubah file halaman saat test
+	"regexp"            // Untuk membaca deklarasi layout di file template
+	"strings"           // Untuk manipulasi string
+	"sync"              // Untuk mengamankan cache template halaman
+	"testing"           // Untuk unit testing
+)
+
+// Layout dan halaman disimpan di sub folder agar tidak bercampur
+// dengan namespace global myTemplates (templates/*.gohtml)
+//
+//go:embed templates/layouts templates/pages
+var viewTemplates embed.FS
+
+// Layout default jika halaman tidak menentukan layout
+const defaultLayout = "base"
+
+// Deklarasi layout di baris pertama file, misal {{/* layout: admin */}}
+var layoutDirectivePattern = regexp.MustCompile(`\{\{-?\s*/\*\s*layout:\s*([\w/-]+)\s*\*/\s*-?\}\}`)
+
+// View engine dengan layout inheritance:
+// setiap halaman mendapatkan salinan (clone) layout sendiri,
+// sehingga dua halaman bisa mendefinisikan block "content" masing-masing
+type ViewEngine struct {
+	FileSystem  fs.FS            // Root berisi folder layouts/ dan pages/
+	Funcs       template.FuncMap // Function yang didaftarkan ke setiap template set
+	Development bool             // true = template dibangun ulang di setiap render
+
+	mutex sync.RWMutex
+	pages map[string]*template.Template
+}
+
+// Membuat ViewEngine dan langsung membangun seluruh halaman
+func NewViewEngine(fileSystem fs.FS, development bool) (*ViewEngine, error) {
+	engine := &ViewEngine{
+		FileSystem:  fileSystem,
+		Funcs:       StandardFuncs(),
+		Development: development,
+	}
+
+	err := engine.Load()
+	if err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// ViewEngine dari embed (lewat overlay agar bisa ditimpa dari disk)
+func ProductionViewEngine() *ViewEngine {
+	engine, err := NewViewEngine(overlaySub(viewTemplates, "templates"), false)
+	if err != nil {
+		panic(err)
+	}
+	return engine
+}
+
+// Membaca nama layout yang dideklarasikan di file template
+func layoutDirective(content []byte) string {
+	match := layoutDirectivePattern.FindSubmatch(content)
+	if match == nil {
+		return ""
+	}
+	return string(match[1])
+}
+
+// Memilih layout halaman secara otomatis:
+// 1. deklarasi {{/* layout: nama */}} di file halaman
+// 2. nama folder halaman jika ada layout dengan nama yang sama (pages/admin/* -> admin)
+// 3. layout default "base"
+func (engine *ViewEngine) selectLayout(page string, content []byte) string {
+	if layout := layoutDirective(content); layout != "" {
+		return layout
+	}
+	directory := path.Dir(page)
+	if directory != "." {
+		_, err := fs.Stat(engine.FileSystem, "layouts/"+path.Base(directory)+".gohtml")
+		if err == nil {
+			return path.Base(directory)
+		}
+	}
+	return defaultLayout
+}
+
+// Membangun template set untuk satu layout beserta seluruh layout induknya
+// Contoh: admin -> base, maka base diparse lebih dulu lalu admin menimpa block milik base
+func (engine *ViewEngine) buildLayout(name string, visited map[string]bool) (*template.Template, string, error) {
+	if visited[name] {
+		return nil, "", fmt.Errorf("layout %s memiliki inheritance melingkar", name)
+	}
+	visited[name] = true
+
+	file := "layouts/" + name + ".gohtml"
+	content, err := fs.ReadFile(engine.FileSystem, file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	parent := layoutDirective(content)
+	if parent == "" {
+		// Layout paling atas, nama template root sama dengan nama layout
+		root, err := template.New(name).Funcs(engine.Funcs).ParseFS(engine.FileSystem, file)
+		return root, name, err
+	}
+
+	parentTemplate, root, err := engine.buildLayout(parent, visited)
+	if err != nil {
+		return nil, "", err
+	}
+	layout, err := parentTemplate.Clone()
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = layout.New(file).Parse(string(content))
+	return layout, root, err
+}
+
+// Membangun template set satu halaman: clone layout lalu parse file halaman di atasnya
+func (engine *ViewEngine) buildPage(page string) (*template.Template, error) {
+	file := "pages/" + page + ".gohtml"
+	content, err := fs.ReadFile(engine.FileSystem, file)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, root, err := engine.buildLayout(engine.selectLayout(page, content), map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	pageTemplate, err := layout.Clone()
+	if err != nil {
+		return nil, err
+	}
+	_, err = pageTemplate.New(file).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	// Template yang dieksekusi adalah root layout (misal "base")
+	return pageTemplate.Lookup(root), nil
+}
+
+// Membangun seluruh halaman di folder pages/
+func (engine *ViewEngine) Load() error {
+	pages := map[string]*template.Template{}
+
+	err := fs.WalkDir(engine.FileSystem, "pages", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || path.Ext(name) != ".gohtml" {
+			return nil
+		}
+
+		page := strings.TrimSuffix(strings.TrimPrefix(name, "pages/"), ".gohtml")
+		pageTemplate, err := engine.buildPage(page)
+		if err != nil {
+			return err
+		}
+		pages[page] = pageTemplate
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	engine.mutex.Lock()
+	engine.pages = pages
+	engine.mutex.Unlock()
+	return nil
+}
+
+// Merender halaman berdasarkan nama, misal "home" atau "admin/dashboard"
+func (engine *ViewEngine) ExecutePage(writer io.Writer, page string, data interface{}) error {
+	if engine.Development {
+		pageTemplate, err := engine.buildPage(page)
+		if err != nil {
+			return err
+		}
+		return pageTemplate.Execute(writer, data)
+	}
+
+	engine.mutex.RLock()
+	pageTemplate, ok := engine.pages[page]
+	engine.mutex.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("halaman %s tidak ditemukan", page)
+	}
+	return pageTemplate.Execute(writer, data)
+}
+
+// Alias ExecutePage agar ViewEngine bisa dipakai oleh Render (TemplateExecutor)
+func (engine *ViewEngine) ExecuteTemplate(writer io.Writer, page string, data interface{}) error {
+	return engine.ExecutePage(writer, page, data)
+}
+
+func TestViewEnginePages(t *testing.T) {
+	engine := ProductionViewEngine()
+
+	data := map[string]interface{}{
+		"Title": "Template Layout Inheritance",
+		"Name":  "Hilmi",
+	}
+
+	// Isi yang hanya boleh muncul di halaman masing-masing
+	tests := []struct {
+		page     string
+		contains []string
+		excludes []string
+	}{
+		{
+			page:     "home",
+			contains: []string{"<title>Template Layout Inheritance</title>", "<h1>Hello Hilmi</h1>"},
+			excludes: []string{"Tentang", "Dashboard", "Admin Panel", "<script"},
+		},
+		{
+			page:     "about",
+			contains: []string{"<title>Belajar Golang Web</title>", "<h1>Tentang Hilmi</h1>"},
+			excludes: []string{"Hello", "Dashboard", "Admin Panel", "<script"},
+		},
+		{
+			// Layout admin bersarang di dalam layout base
+			page: "admin/dashboard",
+			contains: []string{
+				"<!DOCTYPE html>", "<title>Dashboard - Template Layout Inheritance</title>",
+				"<nav>Admin Panel</nav>", "<main>", "<h1>Dashboard</h1>", "</main>",
+				`<script src="/static/index.js"></script>`, "</html>",
+			},
+			excludes: []string{"Hello", "Tentang"},
+		},
+	}
+
+	// Setiap halaman memiliki block "content" sendiri tanpa saling menimpa
+	for _, test := range tests {
+		builder := new(strings.Builder)
+		err := engine.ExecutePage(builder, test.page, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("==", test.page)
+		fmt.Println(builder.String())
+
+		for _, text := range test.contains {
+			if !strings.Contains(builder.String(), text) {
+				t.Errorf("%s: expected %q", test.page, text)
+			}
+		}
+		for _, text := range test.excludes {
+			if strings.Contains(builder.String(), text) {
+				t.Errorf("%s: unexpected %q", test.page, text)
+			}
+		}
+	}
+}
+
+func TestViewEngineDevelopment(t *testing.T) {
+	// Salinan folder layouts dan pages agar file bisa diubah saat test
+	directory := t.TempDir()
+	source, _ := fs.Sub(viewTemplates, "templates")
+	err := os.CopyFS(directory, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := NewViewEngine(os.DirFS(directory), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{
+		"Title": "Development",
+		"Name":  "Hilmi",
+	}
+
+	recorder := httptest.NewRecorder()
+	err = engine.ExecutePage(recorder, "admin/dashboard", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+	if !strings.Contains(string(body), "<h1>Dashboard</h1>") {
+		t.Errorf("unexpected dashboard %q", body)
+	}
+
+	// Perubahan file di folder templates langsung terlihat tanpa restart
+	err = os.WriteFile(filepath.Join(directory, "pages", "admin", "dashboard.gohtml"),
+		[]byte(`{{define "content"}}<h1>Dashboard Baru {{.Name}}</h1>{{end}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder = httptest.NewRecorder()
+	err = engine.ExecutePage(recorder, "admin/dashboard", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(recorder.Result().Body)
+	fmt.Println(string(body))
+	if !strings.Contains(string(body), "<h1>Dashboard Baru Hilmi</h1>") || !strings.Contains(string(body), "<nav>Admin Panel</nav>") {
+		t.Errorf("edited page not picked up: %q", body)
+	}
+}
+
+func TestViewEngineServer(t *testing.T) {
+	engine := ProductionViewEngine()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		Render(writer, request, engine, "home", map[string]interface{}{"Title": "Home", "Name": "Hilmi"})
+	})
+	mux.HandleFunc("/admin", func(writer http.ResponseWriter, request *http.Request) {
+		Render(writer, request, engine, "admin/dashboard", map[string]interface{}{"Title": "Admin", "Name": "Hilmi"})
+	})
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: mux,
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat ViewEngine yang mendukung layout inheritance menggunakan block pada Go template. Setiap halaman mendapatkan clone layout sendiri sehingga block "content", "title", dan "scripts" milik satu halaman tidak menimpa halaman lain seperti pada namespace global myTemplates. Layout dapat mewarisi layout lain (admin mewarisi base), dan layout halaman dipilih otomatis dari deklarasi di file, nama folder halaman, atau layout default.
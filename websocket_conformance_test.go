@@ -0,0 +1,303 @@
+package belajar_golang_web
+
+import (
+	"bytes"             // Untuk membandingkan payload
+	"crypto/rand"       // Untuk masking key frame mentah
+	"encoding/binary"   // Untuk menyusun header frame dan kode close
+	"fmt"               // Untuk output ke console
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk server test di port acak
+	"strings"           // Untuk membuat payload
+	"testing"           // Untuk unit testing
+	"time"              // Untuk batas waktu baca
+)
+
+// Bit RSV pada byte pertama frame
+const (
+	rsv1Bit = 0x40
+	rsv2Bit = 0x20
+	rsv3Bit = 0x10
+)
+
+// Frame mentah yang dikirim client atau diharapkan dari server
+type conformanceFrame struct {
+	fin      bool
+	rsv      byte
+	opcode   int
+	payload  []byte
+	unmasked bool // Melanggar aturan: frame client tanpa mask
+	truncate bool // Hanya header dan 16 byte payload yang dikirim
+}
+
+// Satu kasus uji dengan penomoran seperti Autobahn TestSuite
+type conformanceCase struct {
+	id          string
+	description string
+	compression bool
+	send        []conformanceFrame
+	expect      []conformanceFrame // Frame yang harus diterima secara berurutan sebelum close
+	closeCode   int                // 0 berarti koneksi ditutup normal oleh client di akhir kasus
+}
+
+func frame(opcode int, payload []byte) conformanceFrame {
+	return conformanceFrame{fin: true, opcode: opcode, payload: payload}
+}
+
+func fragment(opcode int, payload []byte, fin bool) conformanceFrame {
+	return conformanceFrame{fin: fin, opcode: opcode, payload: payload}
+}
+
+func textFrame(text string) conformanceFrame {
+	return frame(OpText, []byte(text))
+}
+
+func closeFrame(code int, reason string) conformanceFrame {
+	return frame(OpClose, closePayload(code, reason))
+}
+
+func compressed(opcode int, payload []byte) conformanceFrame {
+	data, _ := deflateMessage(payload)
+	return conformanceFrame{fin: true, rsv: rsv1Bit, opcode: opcode, payload: data}
+}
+
+// Menulis frame apa adanya, termasuk frame yang sengaja melanggar protokol
+func sendConformanceFrame(conn *WebSocketConn, frame conformanceFrame) error {
+	first := frame.rsv | byte(frame.opcode)
+	if frame.fin {
+		first |= 0x80
+	}
+	header := []byte{first}
+
+	var maskBit byte = 0x80
+	if frame.unmasked {
+		maskBit = 0
+	}
+	switch length := len(frame.payload); {
+	case length <= 125:
+		header = append(header, maskBit|byte(length))
+	case length <= 0xffff:
+		header = append(header, maskBit|126)
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header = append(header, maskBit|127)
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	payload := append([]byte(nil), frame.payload...)
+	if !frame.unmasked {
+		var mask [4]byte
+		rand.Read(mask[:])
+		header = append(header, mask[:]...)
+		maskBytes(mask, payload)
+	}
+	if frame.truncate {
+		payload = payload[:16]
+	}
+
+	conn.writeMutex.Lock()
+	defer conn.writeMutex.Unlock()
+	conn.writer.Write(header)
+	conn.writer.Write(payload)
+	return conn.writer.Flush()
+}
+
+// Membaca frame dari server, payload terkompresi dikembalikan ke bentuk aslinya
+func readConformanceFrame(conn *WebSocketConn) (websocketFrame, error) {
+	conn.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	frame, err := conn.readFrame()
+	if err == nil && frame.rsv1 {
+		frame.payload, err = inflateMessage(frame.payload, 0)
+	}
+	return frame, err
+}
+
+func runConformanceCase(server *httptest.Server, test conformanceCase) error {
+	header := http.Header{}
+	if test.compression {
+		header.Set("Sec-WebSocket-Extensions", "permessage-deflate")
+	}
+	conn, _, err := DialWebSocket(websocketURL(server, "/"), header)
+	if err != nil {
+		return err
+	}
+	defer conn.conn.Close()
+	conn.MaxMessageSize = 0 // Client test menerima pesan sebesar apa pun
+
+	if test.compression && !conn.Compression() {
+		return fmt.Errorf("permessage-deflate tidak dinegosiasikan")
+	}
+
+	for _, frame := range test.send {
+		sendConformanceFrame(conn, frame)
+	}
+
+	for index, expected := range test.expect {
+		received, err := readConformanceFrame(conn)
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", index, err)
+		}
+		if received.opcode != expected.opcode || !bytes.Equal(received.payload, expected.payload) {
+			return fmt.Errorf("frame %d: expected opcode %d (%d byte), got opcode %d (%d byte)",
+				index, expected.opcode, len(expected.payload), received.opcode, len(received.payload))
+		}
+	}
+
+	expectedCode := test.closeCode
+	if expectedCode == 0 {
+		sendConformanceFrame(conn, closeFrame(CloseNormal, ""))
+		expectedCode = CloseNormal
+	}
+
+	received, err := readConformanceFrame(conn)
+	if err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	if received.opcode != OpClose {
+		return fmt.Errorf("expected close %d, got opcode %d", expectedCode, received.opcode)
+	}
+	code := CloseNoStatus
+	if len(received.payload) >= 2 {
+		code = int(binary.BigEndian.Uint16(received.payload))
+	}
+	if code != expectedCode {
+		return fmt.Errorf("expected close %d, got %d", expectedCode, code)
+	}
+
+	// Setelah close handshake server harus menutup koneksi TCP
+	_, err = readConformanceFrame(conn)
+	if err == nil {
+		return fmt.Errorf("connection still open after close")
+	}
+	return nil
+}
+
+// Batas pesan server pada kasus 9.x
+const conformanceMaxMessageSize = 256 << 10
+
+func conformanceCases() []conformanceCase {
+	payload := func(size int) []byte {
+		return bytes.Repeat([]byte("*"), size)
+	}
+	text := strings.Repeat("Belajar Golang Web ", 60)
+
+	cases := []conformanceCase{
+		{id: "2.1", description: "ping tanpa payload", send: []conformanceFrame{frame(OpPing, nil)}, expect: []conformanceFrame{frame(OpPong, []byte{})}},
+		{id: "2.3", description: "ping dengan payload biner", send: []conformanceFrame{frame(OpPing, []byte{0x00, 0xff, 0xfe, 0xfd})}, expect: []conformanceFrame{frame(OpPong, []byte{0x00, 0xff, 0xfe, 0xfd})}},
+		{id: "2.4", description: "ping 125 byte", send: []conformanceFrame{frame(OpPing, payload(125))}, expect: []conformanceFrame{frame(OpPong, payload(125))}},
+		{id: "2.5", description: "ping 126 byte", send: []conformanceFrame{frame(OpPing, payload(126))}, closeCode: CloseProtocolError},
+
+		{id: "3.1", description: "RSV1 tanpa ekstensi", send: []conformanceFrame{{fin: true, rsv: rsv1Bit, opcode: OpText, payload: []byte("Hello")}}, closeCode: CloseProtocolError},
+		{id: "3.2", description: "RSV2 setelah pesan valid", send: []conformanceFrame{textFrame("Hello"), {fin: true, rsv: rsv2Bit, opcode: OpText, payload: []byte("Hello")}}, expect: []conformanceFrame{textFrame("Hello")}, closeCode: CloseProtocolError},
+		{id: "3.7", description: "RSV1+RSV2+RSV3 pada ping", send: []conformanceFrame{{fin: true, rsv: rsv1Bit | rsv2Bit | rsv3Bit, opcode: OpPing}}, closeCode: CloseProtocolError},
+
+		{id: "4.1.1", description: "opcode 3", send: []conformanceFrame{frame(3, nil)}, closeCode: CloseProtocolError},
+		{id: "4.1.3", description: "pesan valid, opcode 5, lalu ping", send: []conformanceFrame{textFrame("Hello"), frame(5, nil), frame(OpPing, nil)}, expect: []conformanceFrame{textFrame("Hello")}, closeCode: CloseProtocolError},
+		{id: "4.2.1", description: "opcode 11", send: []conformanceFrame{frame(11, nil)}, closeCode: CloseProtocolError},
+
+		{id: "5.1", description: "ping dipecah", send: []conformanceFrame{fragment(OpPing, []byte("frag1"), false), fragment(opContinuation, []byte("frag2"), true)}, closeCode: CloseProtocolError},
+		{id: "5.2", description: "pong dipecah", send: []conformanceFrame{fragment(OpPong, []byte("frag1"), false), fragment(opContinuation, []byte("frag2"), true)}, closeCode: CloseProtocolError},
+		{id: "5.3", description: "teks dalam dua fragmen", send: []conformanceFrame{fragment(OpText, []byte("frag1"), false), fragment(opContinuation, []byte("frag2"), true)}, expect: []conformanceFrame{textFrame("frag1frag2")}},
+		{id: "5.6", description: "ping di antara fragmen", send: []conformanceFrame{fragment(OpText, []byte("frag1"), false), frame(OpPing, []byte("ping")), fragment(opContinuation, []byte("frag2"), true)}, expect: []conformanceFrame{frame(OpPong, []byte("ping")), textFrame("frag1frag2")}},
+		{id: "5.9", description: "continuation tanpa awal pesan", send: []conformanceFrame{fragment(opContinuation, []byte("fragment"), true)}, closeCode: CloseProtocolError},
+		{id: "5.18", description: "pesan baru sebelum fragmen selesai", send: []conformanceFrame{fragment(OpText, []byte("frag1"), false), fragment(OpText, []byte("frag2"), true)}, closeCode: CloseProtocolError},
+
+		{id: "6.2.1", description: "UTF-8 valid", send: []conformanceFrame{textFrame("Hello-µ@ßöäüàá-UTF-8!!")}, expect: []conformanceFrame{textFrame("Hello-µ@ßöäüàá-UTF-8!!")}},
+		{id: "6.2.3", description: "UTF-8 valid terpotong di tengah karakter", send: []conformanceFrame{fragment(OpText, []byte("Hello-\xc2"), false), fragment(opContinuation, []byte("\xb5@"), true)}, expect: []conformanceFrame{textFrame("Hello-µ@")}},
+		{id: "6.3.1", description: "UTF-8 tidak valid (surrogate)", send: []conformanceFrame{textFrame("\xce\xba\xe1\xbd\xb9\xcf\x83\xce\xbc\xce\xb5\xed\xa0\x80edited")}, closeCode: CloseInvalidPayload},
+		{id: "6.4.1", description: "UTF-8 tidak valid di fragmen kedua", send: []conformanceFrame{fragment(OpText, []byte("\xce\xba\xe1\xbd\xb9"), false), fragment(opContinuation, []byte("\xf4\x90\x80\x80"), true)}, closeCode: CloseInvalidPayload},
+		{id: "6.6.1", description: "UTF-8 overlong", send: []conformanceFrame{textFrame("\xc0\xaf")}, closeCode: CloseInvalidPayload},
+
+		{id: "7.1.3", description: "ping setelah close diabaikan", send: []conformanceFrame{closeFrame(CloseNormal, ""), frame(OpPing, nil)}, closeCode: CloseNormal},
+		{id: "7.3.1", description: "close tanpa payload", send: []conformanceFrame{frame(OpClose, nil)}, closeCode: CloseNoStatus},
+		{id: "7.3.2", description: "close dengan payload 1 byte", send: []conformanceFrame{frame(OpClose, []byte{0x03})}, closeCode: CloseProtocolError},
+		{id: "7.3.6", description: "close dengan alasan 123 byte", send: []conformanceFrame{closeFrame(CloseNormal, strings.Repeat("*", 123))}, closeCode: CloseNormal},
+		{id: "7.5.1", description: "alasan close bukan UTF-8", send: []conformanceFrame{closeFrame(CloseNormal, "\xce\xba\xe1\xbd\xb9\xed\xa0\x80")}, closeCode: CloseInvalidPayload},
+
+		{id: "9.x", description: "pesan 200 KB", send: []conformanceFrame{frame(OpBinary, payload(200<<10))}, expect: []conformanceFrame{frame(OpBinary, payload(200<<10))}},
+		{id: "9.x", description: "frame melebihi batas", send: []conformanceFrame{{fin: true, opcode: OpBinary, payload: payload(conformanceMaxMessageSize + 1), truncate: true}}, closeCode: CloseMessageTooBig},
+		{id: "9.x", description: "fragmen melebihi batas", send: []conformanceFrame{fragment(OpBinary, payload(200<<10), false), fragment(opContinuation, payload(200<<10), true)}, closeCode: CloseMessageTooBig},
+
+		{id: "12.1", description: "balasan terkompresi", compression: true, send: []conformanceFrame{textFrame(text)}, expect: []conformanceFrame{textFrame(text)}},
+		{id: "12.2", description: "pesan terkompresi dari client", compression: true, send: []conformanceFrame{compressed(OpText, []byte(text))}, expect: []conformanceFrame{textFrame(text)}},
+		{id: "13.1", description: "pesan terkompresi dipecah", compression: true, send: func() []conformanceFrame {
+			data, _ := deflateMessage([]byte(text))
+			half := len(data) / 2
+			return []conformanceFrame{
+				{fin: false, rsv: rsv1Bit, opcode: OpText, payload: data[:half]},
+				{fin: true, opcode: opContinuation, payload: data[half:]},
+			}
+		}(), expect: []conformanceFrame{textFrame(text)}},
+		{id: "13.x", description: "RSV1 pada continuation", compression: true, send: []conformanceFrame{fragment(OpText, []byte("frag1"), false), {fin: true, rsv: rsv1Bit, opcode: opContinuation, payload: []byte("frag2")}}, closeCode: CloseProtocolError},
+		{id: "13.x", description: "kompresi melebihi batas setelah dibuka", compression: true, send: []conformanceFrame{compressed(OpBinary, payload(conformanceMaxMessageSize+1))}, closeCode: CloseMessageTooBig},
+
+		{id: "RFC 5.1", description: "frame client tanpa mask", send: []conformanceFrame{{fin: true, opcode: OpText, payload: []byte("Hello"), unmasked: true}}, closeCode: CloseProtocolError},
+	}
+
+	for index, size := range []int{0, 125, 126, 127, 128, 65535, 65536} {
+		cases = append(cases,
+			conformanceCase{id: fmt.Sprintf("1.1.%d", index+1), description: fmt.Sprintf("teks %d byte", size), send: []conformanceFrame{frame(OpText, payload(size))}, expect: []conformanceFrame{frame(OpText, payload(size))}},
+			conformanceCase{id: fmt.Sprintf("1.2.%d", index+1), description: fmt.Sprintf("biner %d byte", size), send: []conformanceFrame{frame(OpBinary, payload(size))}, expect: []conformanceFrame{frame(OpBinary, payload(size))}},
+		)
+	}
+
+	pings := conformanceCase{id: "2.10", description: "10 ping berturut-turut"}
+	for index := 0; index < 10; index++ {
+		data := []byte(fmt.Sprintf("ping %d", index))
+		pings.send = append(pings.send, frame(OpPing, data))
+		pings.expect = append(pings.expect, frame(OpPong, data))
+	}
+	cases = append(cases, pings)
+
+	for _, code := range []int{1000, 1001, 1002, 1003, 1007, 1008, 1009, 1010, 1011, 3000, 3999, 4000, 4999} {
+		cases = append(cases, conformanceCase{id: "7.7", description: fmt.Sprintf("kode close valid %d", code), send: []conformanceFrame{closeFrame(code, "")}, closeCode: code})
+	}
+	for _, code := range []int{0, 999, 1004, 1005, 1006, 1016, 1100, 2000, 2999, 5000, 65535} {
+		cases = append(cases, conformanceCase{id: "7.9", description: fmt.Sprintf("kode close tidak valid %d", code), send: []conformanceFrame{closeFrame(code, "")}, closeCode: CloseProtocolError})
+	}
+	return cases
+}
+
+func conformanceEchoHandler() *WebSocketHandler {
+	return &WebSocketHandler{
+		Upgrader: WebSocketUpgrader{MaxMessageSize: conformanceMaxMessageSize, EnableCompression: true},
+		Serve:    WebSocketEcho,
+	}
+}
+
+// Uji kesesuaian dengan RFC 6455 dan RFC 7692 mengikuti kelompok kasus Autobahn TestSuite
+func TestWebSocketConformance(t *testing.T) {
+	server := httptest.NewServer(conformanceEchoHandler())
+	defer server.Close()
+
+	passed := 0
+	for _, test := range conformanceCases() {
+		err := runConformanceCase(server, test)
+		if err != nil {
+			t.Errorf("%s %s: %v", test.id, test.description, err)
+			continue
+		}
+		passed++
+	}
+	fmt.Printf("%d/%d kasus lulus\n", passed, len(conformanceCases()))
+}
+
+// Server echo untuk Autobahn TestSuite yang asli, jalankan lalu:
+//
+//	docker run -it --rm --network host -v "$PWD/autobahn:/config" -v "$PWD/autobahn/reports:/reports" \
+//	  crossbario/autobahn-testsuite wstest -m fuzzingclient -s /config/fuzzingclient.json
+//
+// dengan fuzzingclient.json berisi {"servers": [{"url": "ws://127.0.0.1:9001"}], "cases": ["*"]}
+func TestWebSocketAutobahnServer(t *testing.T) {
+	server := http.Server{
+		Addr:    "localhost:9001",
+		Handler: &WebSocketHandler{Upgrader: WebSocketUpgrader{MaxMessageSize: 16 << 20, EnableCompression: true}, Serve: WebSocketEcho},
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini berisi uji kesesuaian WebSocket yang bisa dijalankan lokal dengan go test, dengan penomoran kasus mengikuti Autobahn TestSuite: framing dan ukuran payload (1.x), ping/pong (2.x), bit reserved (3.x), opcode tidak dikenal (4.x), fragmentasi (5.x), validasi UTF-8 (6.x), close handshake dan kode close (7.x), batas ukuran pesan (9.x), serta permessage-deflate (12.x dan 13.x). Client test mengirim frame mentah, termasuk yang sengaja melanggar protokol, lalu memeriksa balasan server beserta kode close-nya. Untuk pengujian penuh tersedia server echo di port 9001 yang bisa diuji dengan fuzzingclient Autobahn.
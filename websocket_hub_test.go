@@ -0,0 +1,430 @@
+package belajar_golang_web
+
+import (
+	"encoding/json"     // Untuk pesan chat
+	"fmt"               // Untuk output ke console
+	"net/http"          // Package utama HTTP server & handler
+	"net/http/httptest" // Untuk server test di port acak
+	"sync"              // Untuk mengamankan daftar room
+	"testing"           // Untuk unit testing
+	"time"              // Untuk ping berkala
+)
+
+// Pesan yang menunggu dikirim ke satu client
+type hubMessage struct {
+	opcode int
+	data   []byte
+}
+
+// Client yang terhubung ke Hub
+// Pesan dikirim lewat channel agar broadcaster tidak tertahan oleh client yang lambat
+type HubClient struct {
+	Conn  *WebSocketConn
+	Name  string
+	hub   *Hub
+	send  chan hubMessage
+	rooms map[string]bool // Dijaga oleh mutex milik Hub
+}
+
+// Mengirim pesan ke client ini, false jika client sudah terputus
+func (client *HubClient) Send(opcode int, data []byte) bool {
+	client.hub.mutex.RLock()
+	defer client.hub.mutex.RUnlock()
+	return client.hub.trySend(client, hubMessage{opcode: opcode, data: data})
+}
+
+// Room yang sedang diikuti client
+func (client *HubClient) Rooms() []string {
+	client.hub.mutex.RLock()
+	defer client.hub.mutex.RUnlock()
+
+	rooms := make([]string, 0, len(client.rooms))
+	for room := range client.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// Memeriksa apakah client sedang mengikuti room
+func (client *HubClient) InRoom(room string) bool {
+	client.hub.mutex.RLock()
+	defer client.hub.mutex.RUnlock()
+	return client.rooms[room]
+}
+
+// Kumpulan koneksi WebSocket yang dikelompokkan per room
+type Hub struct {
+	SendBuffer   int           // Kapasitas antrean pesan per client
+	PingInterval time.Duration // Jeda ping untuk mendeteksi koneksi yang mati
+
+	// Dipanggil untuk setiap pesan dari client, default meneruskan ke semua room client
+	OnMessage func(client *HubClient, opcode int, data []byte)
+
+	mutex   sync.RWMutex
+	clients map[*HubClient]struct{}
+	rooms   map[string]map[*HubClient]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		SendBuffer:   32,
+		PingInterval: 30 * time.Second,
+		clients:      map[*HubClient]struct{}{},
+		rooms:        map[string]map[*HubClient]struct{}{},
+	}
+}
+
+// Harus dipanggil saat mutex sudah dikunci (read lock cukup)
+// Client yang antreannya penuh diputus; pembacaannya gagal lalu Serve melepasnya dari Hub
+func (hub *Hub) trySend(client *HubClient, message hubMessage) bool {
+	if _, ok := hub.clients[client]; !ok {
+		return false
+	}
+	select {
+	case client.send <- message:
+		return true
+	default:
+		client.Conn.conn.Close()
+		return false
+	}
+}
+
+func (hub *Hub) Join(client *HubClient, room string) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if _, ok := hub.clients[client]; !ok {
+		return
+	}
+	if hub.rooms[room] == nil {
+		hub.rooms[room] = map[*HubClient]struct{}{}
+	}
+	hub.rooms[room][client] = struct{}{}
+	client.rooms[room] = true
+}
+
+func (hub *Hub) Leave(client *HubClient, room string) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	hub.leave(client, room)
+}
+
+func (hub *Hub) leave(client *HubClient, room string) {
+	delete(hub.rooms[room], client)
+	if len(hub.rooms[room]) == 0 {
+		delete(hub.rooms, room)
+	}
+	delete(client.rooms, room)
+}
+
+// Mengirim pesan ke semua client di room, room kosong berarti semua client
+// Mengembalikan jumlah client yang menerima
+func (hub *Hub) Broadcast(room string, opcode int, data []byte) int {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+
+	members := hub.clients
+	if room != "" {
+		members = hub.rooms[room]
+	}
+
+	delivered := 0
+	for client := range members {
+		if hub.trySend(client, hubMessage{opcode: opcode, data: data}) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// Mengirim pesan ke anggota beberapa room sekaligus
+// Client yang mengikuti lebih dari satu room tetap hanya menerima satu kali
+func (hub *Hub) BroadcastRooms(rooms []string, opcode int, data []byte) int {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+
+	sent := map[*HubClient]bool{}
+	delivered := 0
+	for _, room := range rooms {
+		for client := range hub.rooms[room] {
+			if sent[client] {
+				continue
+			}
+			sent[client] = true
+			if hub.trySend(client, hubMessage{opcode: opcode, data: data}) {
+				delivered++
+			}
+		}
+	}
+	return delivered
+}
+
+// Jumlah client di room, room kosong berarti semua client
+func (hub *Hub) Count(room string) int {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+	if room == "" {
+		return len(hub.clients)
+	}
+	return len(hub.rooms[room])
+}
+
+// Menjalankan koneksi sampai terputus: mendaftarkan client, bergabung ke rooms,
+// lalu membaca pesan; penulisan dilakukan goroutine terpisah dari antrean send
+func (hub *Hub) Serve(conn *WebSocketConn, name string, rooms ...string) {
+	client := &HubClient{
+		Conn:  conn,
+		Name:  name,
+		hub:   hub,
+		send:  make(chan hubMessage, hub.SendBuffer),
+		rooms: map[string]bool{},
+	}
+
+	hub.mutex.Lock()
+	hub.clients[client] = struct{}{}
+	hub.mutex.Unlock()
+	for _, room := range rooms {
+		hub.Join(client, room)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		hub.writePump(client)
+	}()
+
+	for {
+		opcode, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		if hub.OnMessage != nil {
+			hub.OnMessage(client, opcode, data)
+			continue
+		}
+		hub.BroadcastRooms(client.Rooms(), opcode, data)
+	}
+
+	hub.mutex.Lock()
+	for room := range client.rooms {
+		hub.leave(client, room)
+	}
+	delete(hub.clients, client)
+	close(client.send)
+	hub.mutex.Unlock()
+	<-done
+}
+
+func (hub *Hub) writePump(client *HubClient) {
+	ping := time.NewTicker(hub.PingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case message, ok := <-client.send:
+			if !ok {
+				client.Conn.Close(CloseGoingAway, "")
+				return
+			}
+			err := client.Conn.WriteMessage(message.opcode, message.data)
+			if err != nil {
+				client.Conn.conn.Close()
+				return
+			}
+		case <-ping.C:
+			err := client.Conn.WriteControl(OpPing, nil)
+			if err != nil {
+				client.Conn.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// Handler yang menghubungkan setiap koneksi ke Hub, room dibaca dari ?room= dan nama dari ?name=
+func (hub *Hub) Handler(upgrader WebSocketUpgrader) *WebSocketHandler {
+	return &WebSocketHandler{
+		Upgrader: upgrader,
+		Serve: func(conn *WebSocketConn, request *http.Request) {
+			hub.Serve(conn, request.URL.Query().Get("name"), request.URL.Query()["room"]...)
+		},
+	}
+}
+
+// Perintah dari client pada contoh chat
+type ChatCommand struct {
+	Action string `json:"action"` // join, leave, atau message
+	Room   string `json:"room"`
+	Text   string `json:"text,omitempty"`
+}
+
+// Pesan chat yang dikirim ke anggota room
+type ChatMessage struct {
+	Room string `json:"room"`
+	From string `json:"from"`
+	Text string `json:"text"`
+}
+
+// Contoh chat: client bergabung ke room dan mengirim pesan dengan perintah JSON
+func chatHub() *Hub {
+	hub := NewHub()
+	hub.OnMessage = func(client *HubClient, opcode int, data []byte) {
+		var command ChatCommand
+		err := json.Unmarshal(data, &command)
+		if err != nil || command.Room == "" {
+			client.Send(OpText, []byte(`{"error":"perintah tidak valid"}`))
+			return
+		}
+
+		switch command.Action {
+		case "join":
+			hub.Join(client, command.Room)
+		case "leave":
+			hub.Leave(client, command.Room)
+		case "message":
+			// Hanya anggota room yang boleh mengirim pesan ke room tersebut
+			if !client.InRoom(command.Room) {
+				client.Send(OpText, []byte(`{"error":"belum bergabung ke room"}`))
+				return
+			}
+			message, _ := json.Marshal(ChatMessage{Room: command.Room, From: client.Name, Text: command.Text})
+			hub.Broadcast(command.Room, OpText, message)
+		}
+	}
+	return hub
+}
+
+// Membaca satu pesan dengan batas waktu agar test tidak menggantung
+func readWithTimeout(conn *WebSocketConn) (string, error) {
+	conn.conn.SetReadDeadline(time.Now().Add(time.Second))
+	defer conn.conn.SetReadDeadline(time.Time{})
+	_, data, err := conn.ReadMessage()
+	return string(data), err
+}
+
+// Menunggu sampai kondisi terpenuhi, karena Join dan Leave terjadi di goroutine server
+func waitFor(condition func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return true
+}
+
+func TestHubBroadcast(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(hub.Handler(WebSocketUpgrader{}))
+	defer server.Close()
+
+	hilmi, _, _ := DialWebSocket(websocketURL(server, "/?name=hilmi&room=golang&room=web"), nil)
+	budi, _, _ := DialWebSocket(websocketURL(server, "/?name=budi&room=golang"), nil)
+	joko, _, _ := DialWebSocket(websocketURL(server, "/?name=joko&room=web"), nil)
+	if !waitFor(func() bool { return hub.Count("") == 3 }) {
+		t.Fatal("clients not registered")
+	}
+	fmt.Println("golang:", hub.Count("golang"), "web:", hub.Count("web"))
+
+	delivered := hub.Broadcast("golang", OpText, []byte("Halo Gophers"))
+	fmt.Println("delivered:", delivered)
+	for _, conn := range []*WebSocketConn{hilmi, budi} {
+		message, err := readWithTimeout(conn)
+		fmt.Println(message, err)
+		if message != "Halo Gophers" {
+			t.Errorf("unexpected message %q", message)
+		}
+	}
+
+	// Tanpa OnMessage, pesan client diteruskan ke semua room yang diikutinya
+	joko.WriteMessage(OpText, []byte("dari joko"))
+	message, _ := readWithTimeout(hilmi)
+	fmt.Println(message)
+	if message != "dari joko" {
+		t.Errorf("expected message from room web, got %q", message)
+	}
+
+	// hilmi ada di golang dan web, tetapi hanya menerima satu salinan
+	delivered = hub.BroadcastRooms([]string{"golang", "web"}, OpText, []byte("sekali saja"))
+	fmt.Println("delivered:", delivered)
+	if delivered != 3 {
+		t.Errorf("expected 3 unique recipients, got %d", delivered)
+	}
+	hub.Broadcast("web", OpText, []byte("penanda"))
+	for _, expected := range []string{"sekali saja", "penanda"} {
+		message, _ := readWithTimeout(hilmi)
+		fmt.Println(message)
+		if message != expected {
+			t.Errorf("expected %q, got %q", expected, message)
+		}
+	}
+
+	budi.Close(CloseNormal, "")
+	readWithTimeout(budi)
+	if !waitFor(func() bool { return hub.Count("golang") == 1 }) {
+		t.Errorf("client not removed from room, count %d", hub.Count("golang"))
+	}
+
+	hilmi.Close(CloseNormal, "")
+	joko.Close(CloseNormal, "")
+}
+
+func TestChatHub(t *testing.T) {
+	hub := chatHub()
+	server := httptest.NewServer(hub.Handler(WebSocketUpgrader{}))
+	defer server.Close()
+
+	hilmi, _, _ := DialWebSocket(websocketURL(server, "/?name=hilmi"), nil)
+	budi, _, _ := DialWebSocket(websocketURL(server, "/?name=budi"), nil)
+	defer hilmi.Close(CloseNormal, "")
+	defer budi.Close(CloseNormal, "")
+
+	for _, conn := range []*WebSocketConn{hilmi, budi} {
+		conn.WriteMessage(OpText, []byte(`{"action":"join","room":"golang"}`))
+	}
+	if !waitFor(func() bool { return hub.Count("golang") == 2 }) {
+		t.Fatal("clients did not join room")
+	}
+
+	// joko tidak bergabung ke golang sehingga pesannya ditolak dan tidak sampai ke room
+	joko, _, _ := DialWebSocket(websocketURL(server, "/?name=joko"), nil)
+	defer joko.Close(CloseNormal, "")
+	joko.WriteMessage(OpText, []byte(`{"action":"message","room":"golang","text":"menyusup"}`))
+	message, _ := readWithTimeout(joko)
+	fmt.Println(message)
+	if message != `{"error":"belum bergabung ke room"}` {
+		t.Errorf("expected membership error, got %q", message)
+	}
+
+	budi.WriteMessage(OpText, []byte(`{"action":"message","room":"golang","text":"Halo semua"}`))
+	for _, conn := range []*WebSocketConn{hilmi, budi} {
+		message, err := readWithTimeout(conn)
+		fmt.Println(message, err)
+		if message != `{"room":"golang","from":"budi","text":"Halo semua"}` {
+			t.Errorf("unexpected chat message %q", message)
+		}
+	}
+
+	hilmi.WriteMessage(OpText, []byte(`bukan json`))
+	message, _ = readWithTimeout(hilmi)
+	fmt.Println(message)
+}
+
+func TestChatServer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/chat", chatHub().Handler(WebSocketUpgrader{EnableCompression: true}))
+
+	server := http.Server{
+		Addr:    "localhost:8080",
+		Handler: mux,
+	}
+
+	err := server.ListenAndServe()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Kesimpulan:
+// Kode ini membuat Hub untuk mengelola banyak koneksi WebSocket yang dikelompokkan per room. Setiap client memiliki antrean pesan dan goroutine penulis sendiri, sehingga Broadcast tidak pernah tertahan oleh client yang lambat; client yang antreannya penuh diputus. Hub juga mengirim ping berkala untuk mendeteksi koneksi mati dan melepas client dari semua room saat koneksi berakhir. Pesan yang diteruskan ke beberapa room sekaligus dikirim lewat BroadcastRooms sehingga client yang berbagi lebih dari satu room dengan pengirim tetap menerima satu salinan. Contoh chat menunjukkan cara memakai OnMessage untuk menerima perintah JSON join, leave, dan message dari client, dan perintah message hanya diterima dari client yang sudah bergabung ke room tujuan.